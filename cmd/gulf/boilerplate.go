@@ -3,29 +3,49 @@
 package main
 
 import (
+	"context"
 	"fmt"
+	"io"
+	"log"
 	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"runtime"
+	"syscall"
 	"time"
 
 	"github.com/SaidinWoT/gulf/glob"
 	"github.com/SaidinWoT/gulf/stream"
+	"github.com/SaidinWoT/gulf/task"
 	"github.com/SaidinWoT/gulf/task/watch"
 	"github.com/SaidinWoT/gulf/util"
 )
 
 // Gulf is a simple struct to bring together gulf's functionality.
 type Gulf struct {
-	s     *watch.Set
-	glob  func(string) ([]string, error)
-	watch bool
+	s      *watch.Set
+	glob   func(string) ([]string, error)
+	watch  bool
+	reload bool
 }
 
 // New creates a Gulf with an empty task Set.
 func New() *Gulf {
-	return &Gulf{
+	g := &Gulf{
 		s:    watch.New(),
 		glob: glob.Glob,
 	}
+	g.SetOption(Errors(printError), Results(func(_ string, err error) {
+		printError(err)
+	}))
+	return g
+}
+
+func printError(err error) {
+	if err != nil {
+		fmt.Println(err)
+	}
 }
 
 type Option func(*Gulf) error
@@ -44,10 +64,19 @@ func (g *Gulf) SetOption(opts ...Option) error {
 // Globber returns an Option that sets a Gulf's globbing function to fn.
 func Globber(fn func(string) ([]string, error)) Option {
 	return func(g *Gulf) error {
+		g.glob = fn
 		return g.s.SetOption(watch.Globber(fn))
 	}
 }
 
+// Ignore returns an Option that excludes names ignored by the provided ignore files from Src and from watching.
+func Ignore(files ...string) Option {
+	return func(g *Gulf) error {
+		g.glob = glob.GlobFunc(glob.IgnoreFiles(files...))
+		return g.s.SetOption(watch.Globber(g.glob), watch.Ignore(files...))
+	}
+}
+
 // Matcher returns an Option that sets a Gulf's matcher function fn.
 func Matcher(fn func(string, string) (bool, error)) Option {
 	return func(g *Gulf) error {
@@ -55,6 +84,13 @@ func Matcher(fn func(string, string) (bool, error)) Option {
 	}
 }
 
+// Patterns returns an Option that sets the glob Options with which watched patterns are matched.
+func Patterns(opts ...glob.Option) Option {
+	return func(g *Gulf) error {
+		return g.s.SetOption(watch.Patterns(opts...))
+	}
+}
+
 // WatchDelay returns an Option that sets the delay used to detect unique file events.
 func Delay(d time.Duration) Option {
 	return func(g *Gulf) error {
@@ -62,6 +98,62 @@ func Delay(d time.Duration) Option {
 	}
 }
 
+// Grace returns an Option that sets the time processes started by Serve are given to exit.
+func Grace(d time.Duration) Option {
+	return func(g *Gulf) error {
+		return g.s.SetOption(watch.Grace(d))
+	}
+}
+
+// Running returns an Option that sets how watched tasks triggered while already running are handled.
+func Running(p watch.Policy) Option {
+	return func(g *Gulf) error {
+		return g.s.SetOption(watch.Running(p))
+	}
+}
+
+// Errors returns an Option that sets a function to be called with any error encountered while watching.
+func Errors(fn func(error)) Option {
+	return func(g *Gulf) error {
+		return g.s.SetOption(watch.Errors(fn))
+	}
+}
+
+// Results returns an Option that sets a function to be called with the result of every watched task.
+func Results(fn func(name string, err error)) Option {
+	return func(g *Gulf) error {
+		return g.s.SetOption(watch.Results(fn))
+	}
+}
+
+// Poll returns an Option that makes Watch poll the filesystem every interval.
+func Poll(interval time.Duration) Option {
+	return func(g *Gulf) error {
+		return g.s.SetOption(watch.Poll(interval))
+	}
+}
+
+// Output returns an Option that sets the io.Writer all task output is written to.
+func Output(w io.Writer) Option {
+	return func(g *Gulf) error {
+		return g.s.Set.SetOption(task.Output(w))
+	}
+}
+
+// Color returns an Option that enables or disables colorizing task name prefixes.
+func Color(on bool) Option {
+	return func(g *Gulf) error {
+		return g.s.Set.SetOption(task.Color(on))
+	}
+}
+
+// Buffer returns an Option that enables or disables holding each task's output until it completes.
+func Buffer(on bool) Option {
+	return func(g *Gulf) error {
+		return g.s.Set.SetOption(task.Buffer(on))
+	}
+}
+
 // Src provides a simple wrapper around stream.Src.
 // It parses patterns with f's globbing function and provides those to stream.Src.
 //
@@ -70,7 +162,8 @@ func Delay(d time.Duration) Option {
 // Globstar (**) matches 0 or more directories.
 // Globs (*) only include dotfiles if there is an explicit dot before the glob character.
 func (g *Gulf) Src(patterns ...string) stream.Stream {
-	filenames := glob.Parse(g.glob, patterns...)
+	filenames, err := glob.Parse(g.glob, patterns...)
+	printError(err)
 	m := util.SrcFiles(filenames...)
 	return stream.Src(m...)
 }
@@ -82,6 +175,52 @@ func (g *Gulf) Task(name string, fn func() error, deps ...string) {
 	g.s.Task(name, fn, deps...)
 }
 
+// TaskContext adds a task accepting a Context to g's task Set.
+func (g *Gulf) TaskContext(name string, fn func(context.Context) error, deps ...string) {
+	g.s.TaskContext(name, fn, deps...)
+}
+
+// Sh returns a task function which runs the named program with the given arguments.
+func (g *Gulf) Sh(name string, args ...string) func(context.Context) error {
+	return task.Cmd(name, args...)
+}
+
+// Writer returns an io.Writer for the named task's output.
+func (g *Gulf) Writer(name string) io.Writer {
+	return g.s.Writer(name)
+}
+
+// Logger returns a *log.Logger writing to the named task's Writer.
+func (g *Gulf) Logger(name string) *log.Logger {
+	return g.s.Logger(name)
+}
+
+// Series returns a task function which runs each of the provided tasks in order.
+// Pass it to TaskContext so that it is cancelled along with the task.
+func (g *Gulf) Series(tasks ...interface{}) func(context.Context) error {
+	return g.s.Series(tasks...)
+}
+
+// Parallel returns a task function which runs all of the provided tasks concurrently.
+func (g *Gulf) Parallel(tasks ...interface{}) func(context.Context) error {
+	return g.s.Parallel(tasks...)
+}
+
+// Resource declares a named resource which at most capacity tasks may hold at once.
+func (g *Gulf) Resource(name string, capacity int) {
+	g.s.Resource(name, capacity)
+}
+
+// Use marks the named task as holding the provided resources while it runs.
+func (g *Gulf) Use(name string, resources ...string) {
+	g.s.Use(name, resources...)
+}
+
+// Observe registers observers to be notified of task lifecycle events.
+func (g *Gulf) Observe(obs ...task.Observer) {
+	g.s.Observe(obs...)
+}
+
 // Watch adds a set of patterns to be watched with corresponding tasks.
 // There is no need to use Start.
 func (g *Gulf) Watch(patterns []string, tasks ...string) {
@@ -89,14 +228,84 @@ func (g *Gulf) Watch(patterns []string, tasks ...string) {
 	g.watch = true
 }
 
+// WatchOp is like Watch, but only executes the tasks for the operations in ops.
+func (g *Gulf) WatchOp(ops watch.Op, patterns []string, tasks ...string) {
+	g.s.WatchOp(ops, patterns, tasks...)
+	g.watch = true
+}
+
+// Serve registers a task which (re)starts a long-running process when patterns change.
+func (g *Gulf) Serve(name string, patterns []string, cmd []string, deps ...string) {
+	g.s.Serve(name, patterns, cmd, deps...)
+	g.watch = true
+}
+
+// BuildFiles are the files which, when changed while watching, cause the gulf binary to rebuild itself.
+var BuildFiles = []string{"gulf.go"}
+
+const reloadTask = "gulf:reload"
+
+// watchBuild watches BuildFiles to rebuild the binary if it was built by the gulf command,
+// which provides its own path in the GULFCMD environment variable.
+// Binaries run by "gulf -r" live elsewhere and need no rebuilding.
+func (g *Gulf) watchBuild() {
+	gulf := os.Getenv("GULFCMD")
+	if gulf == "" {
+		return
+	}
+	exe, err := os.Executable()
+	if err != nil {
+		return
+	}
+	wd, err := os.Getwd()
+	if err != nil || exe != filepath.Join(wd, "gulf") {
+		return
+	}
+	g.s.Task(reloadTask, func() error {
+		cmd := exec.Command(gulf, "-B")
+		cmd.Stdout, cmd.Stderr = os.Stdout, os.Stderr
+		if err := cmd.Run(); err != nil {
+			return err
+		}
+		g.reload = true
+		g.s.Stop()
+		return nil
+	})
+	g.s.Watch(BuildFiles, reloadTask)
+}
+
+// reexec replaces the process with the rebuilt binary, run with the same arguments.
+// Where that is unsupported, as on Windows, the binary is run as a child and reexec exits once it does.
+func reexec() {
+	exe, err := os.Executable()
+	if err != nil {
+		printError(err)
+		return
+	}
+	if runtime.GOOS != "windows" {
+		printError(syscall.Exec(exe, os.Args, os.Environ()))
+		return
+	}
+	cmd := exec.Command(exe, os.Args[1:]...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	err = cmd.Run()
+	if exit, ok := err.(*exec.ExitError); ok {
+		os.Exit(exit.ExitCode())
+	}
+	printError(err)
+}
+
 func main() {
 	g := New()
 	Tasks(g)
-	err := g.s.Exec(os.Args[1])
+	printError(g.s.Exec(os.Args[1]))
 	if g.watch {
-		g.s.Start()
-	}
-	if err != nil {
-		fmt.Println(err)
+		g.watchBuild()
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		printError(g.s.Run(ctx))
+		stop()
+		if g.reload {
+			reexec()
+		}
 	}
 }
@@ -14,13 +14,15 @@ import (
 const boilerplateLoc = "/src/github.com/SaidinWoT/gulf/cmd/gulf/boilerplate.go"
 
 var (
-	runFlag   bool
-	buildFlag bool
+	runFlag       bool
+	buildFlag     bool
+	buildOnlyFlag bool
 )
 
 func init() {
 	flag.BoolVar(&runFlag, "r", false, "Run a task from gulf.go without building the binary.")
 	flag.BoolVar(&buildFlag, "b", false, "Rebuild the binary regardless of gulf.go's modtime.")
+	flag.BoolVar(&buildOnlyFlag, "B", false, "Rebuild the binary and exit without running a task.")
 }
 
 func main() {
@@ -39,6 +41,14 @@ func main() {
 		fmt.Println("There was an error accessing gulf.go. This is problematic.")
 		return
 	}
+	if buildOnlyFlag {
+		// The gulf binary uses this to rebuild itself while watching.
+		fmt.Println("Rebuilding the local gulf binary.")
+		if err := rebuild(wd); err != nil {
+			os.Exit(1)
+		}
+		return
+	}
 	if rawTime.After(binTime) || buildFlag {
 		fmt.Println("Rebuilding the local gulf binary.")
 		rebuild(wd)
@@ -48,6 +58,10 @@ func main() {
 		task = flag.Arg(0)
 	}
 	fmt.Println("Running task", task)
+	// The gulf binary rebuilds itself with this command while watching.
+	if exe, err := os.Executable(); err == nil {
+		os.Setenv("GULFCMD", exe)
+	}
 	runCmd(filepath.Join(wd, "gulf"), task)
 }
 
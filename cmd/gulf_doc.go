@@ -3,11 +3,21 @@
 package cmd
 
 import (
+	"context"
+	"io"
+	"log"
 	"time"
 
+	"github.com/SaidinWoT/gulf/glob"
 	"github.com/SaidinWoT/gulf/stream"
+	"github.com/SaidinWoT/gulf/task"
+	"github.com/SaidinWoT/gulf/task/watch"
 )
 
+// BuildFiles are the files which, when changed while watching, cause the gulf binary to rebuild itself.
+// The gulf binary is rebuilt with `gulf -B`.
+var BuildFiles = []string{"gulf.go"}
+
 // Gulf is a simple struct to bring together gulf's functionality.
 type Gulf struct {
 	unexported struct{}
@@ -32,13 +42,30 @@ func Globber(fn func(string) ([]string, error)) Option {
 	return nopOption
 }
 
+// Ignore returns an Option that excludes names ignored by the provided gitignore-style files
+// (.gitignore and .gulfignore, if none are provided) from Src and from watching.
+// It replaces the globbing function set by Globber.
+//
+// Default: nothing is ignored
+func Ignore(files ...string) Option {
+	return nopOption
+}
+
 // Matcher returns an Option that sets a Gulf's matcher function fn.
 //
-// Default: gulf/glob.Match
+// Default: patterns compiled by gulf/glob.Compile
 func Matcher(fn func(string, string) (bool, error)) Option {
 	return nopOption
 }
 
+// Patterns returns an Option that sets the glob Options with which watched patterns are matched,
+// such as glob.CaseInsensitive or glob.MatchBase. They are not used with a Matcher.
+//
+// Default: no Options
+func Patterns(opts ...glob.Option) Option {
+	return nopOption
+}
+
 // WatchDelay returns an Option that sets the delay used to detect unique file events.
 //
 // Default: 10 * time.Millisecond
@@ -46,6 +73,69 @@ func Delay(d time.Duration) Option {
 	return nopOption
 }
 
+// Grace returns an Option that sets the time processes started by Serve are given
+// to exit after being interrupted, before they are killed.
+//
+// Default: 5 * time.Second
+func Grace(d time.Duration) Option {
+	return nopOption
+}
+
+// Running returns an Option that sets how watched tasks triggered while already running are handled:
+// either watch.Queue, which runs them once more afterward, or watch.Restart,
+// which cancels the running execution's Context first.
+//
+// Default: watch.Queue
+func Running(p watch.Policy) Option {
+	return nopOption
+}
+
+// Errors returns an Option that sets a function to be called with any error encountered while watching.
+//
+// Default: print the error
+func Errors(fn func(error)) Option {
+	return nopOption
+}
+
+// Results returns an Option that sets a function to be called with the name and result
+// of every task executed because of a filesystem event, such as to display pass or fail.
+//
+// Default: print the error of any failed task
+func Results(fn func(name string, err error)) Option {
+	return nopOption
+}
+
+// Poll returns an Option that makes Watch poll the filesystem every interval rather than
+// rely on the operating system's filesystem events, which are unavailable on network mounts
+// and some container volumes.
+//
+// Default: filesystem events via fsnotify
+func Poll(interval time.Duration) Option {
+	return nopOption
+}
+
+// Output returns an Option that sets the io.Writer all task output is written to.
+//
+// Default: os.Stdout
+func Output(w io.Writer) Option {
+	return nopOption
+}
+
+// Color returns an Option that enables or disables colorizing task name prefixes.
+//
+// Default: false
+func Color(on bool) Option {
+	return nopOption
+}
+
+// Buffer returns an Option that enables or disables holding each task's output
+// until it completes, so that output from parallel tasks is never interleaved.
+//
+// Default: false
+func Buffer(on bool) Option {
+	return nopOption
+}
+
 // Src provides a simple wrapper around stream.Src.
 // It parses patterns with g's globbing function and provides those to stream.Src.
 //
@@ -60,6 +150,79 @@ func (g *Gulf) Src(patterns ...string) stream.Stream {
 // Task adds a task to g's task Set.
 func (g *Gulf) Task(name string, fn func() error, deps ...string) {}
 
+// TaskContext is like Task, but registers a function which accepts a Context.
+// The Context carries the task's name and Writer, and is canceled if execution is abandoned.
+func (g *Gulf) TaskContext(name string, fn func(context.Context) error, deps ...string) {}
+
+// Sh returns a task function which runs the named program with the given arguments.
+// Its output is written to the task's Writer, it is killed along with any children
+// if the task's Context is canceled, and a non-zero exit status is reported as a task.ErrCmd.
+//
+//	g.TaskContext("test", g.Sh("go", "test", "./..."))
+func (g *Gulf) Sh(name string, args ...string) func(context.Context) error {
+	return nil
+}
+
+// Writer returns an io.Writer for the named task's output.
+// Each line written is prefixed with the task name,
+// which makes it suitable for the Stdout and Stderr of subprocesses.
+func (g *Gulf) Writer(name string) io.Writer {
+	return nil
+}
+
+// Logger returns a *log.Logger writing to the named task's Writer.
+func (g *Gulf) Logger(name string) *log.Logger {
+	return nil
+}
+
+// Series returns a task function which runs each of the provided tasks in order,
+// stopping at the first required task to fail.
+// Each task may be the name of a task registered with Task, a func() error, or a func(context.Context) error
+// (such as one returned by Sh).
+// Pass it to TaskContext so that the tasks within it are cancelled along with it,
+// and so that their output is prefixed with its name:
+//	g.TaskContext("build", g.Series("clean", g.Sh("go", "build")))
+func (g *Gulf) Series(tasks ...interface{}) func(context.Context) error {
+	return nil
+}
+
+// Parallel returns a task function which runs all of the provided tasks concurrently.
+// The tasks accepted are the same as for Series.
+func (g *Gulf) Parallel(tasks ...interface{}) func(context.Context) error {
+	return nil
+}
+
+// Resource declares a named resource which at most capacity tasks may hold at once.
+// Tasks sharing a resource will not run concurrently beyond its capacity,
+// even if they are unrelated in the dependency graph.
+func (g *Gulf) Resource(name string, capacity int) {}
+
+// Use marks the named task as holding the provided resources while it runs.
+// Resources which have not been declared with Resource have a capacity of 1.
+func (g *Gulf) Use(name string, resources ...string) {}
+
+// Observe registers observers to be notified of task lifecycle events,
+// such as a task starting, succeeding, failing, or being skipped.
+func (g *Gulf) Observe(obs ...task.Observer) {}
+
 // Watch adds a set of patterns to be watched with corresponding tasks.
-// There is no need to use Start.
+// There is no need to use Start; watching begins once the requested task completes,
+// and continues until gulf is interrupted.
+//
+// While watching, changes to any of BuildFiles rebuild the gulf binary,
+// which is then run again with the same arguments.
+//
+// Tasks registered with TaskContext may retrieve the files changed to trigger them
+// with gulf/task/watch.Changes.
 func (g *Gulf) Watch(patterns []string, tasks ...string) {}
+
+// WatchOp is like Watch, but only executes the tasks for the operations in ops,
+// such as watch.Create|watch.Write, which are defined in gulf/task/watch.
+func (g *Gulf) WatchOp(ops watch.Op, patterns []string, tasks ...string) {}
+
+// Serve registers a task named name which starts cmd as a long-running process,
+// such as a development server, and watches patterns to execute it.
+// Each execution stops the previous process and starts a new one once deps have succeeded.
+//
+//	g.Serve("serve", []string{"**/*.go"}, []string{"go", "run", "./server"}, "build")
+func (g *Gulf) Serve(name string, patterns []string, cmd []string, deps ...string) {}
@@ -0,0 +1,153 @@
+package glob
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Expand returns the patterns produced by bash-style brace expansion of pattern.
+//
+//	'{a,b,c}' expands to one pattern for each comma-separated alternative.
+//	'{x..y}' expands to each integer from x to y, zero-padded if either is written with a leading zero.
+//	'{x..y..n}' expands to every nth integer from x to y.
+//	'{a..e}' expands to each letter from a to e.
+//
+// Braces may be nested, and are left as-is if they contain neither a comma nor a sequence.
+// A brace preceded by a backslash is not expanded.
+func Expand(pattern string) []string {
+	for i := 0; i < len(pattern); i++ {
+		switch pattern[i] {
+		case '\\':
+			i++
+		case '{':
+			j := closingBrace(pattern, i)
+			if j < 0 {
+				return []string{pattern}
+			}
+			alts := alternatives(pattern[i+1 : j])
+			if alts == nil {
+				continue
+			}
+			var ps []string
+			for _, alt := range alts {
+				ps = append(ps, Expand(pattern[:i]+alt+pattern[j+1:])...)
+			}
+			return ps
+		}
+	}
+	return []string{pattern}
+}
+
+// closingBrace returns the index of the brace closing the one at p[i], or -1 if it is unclosed.
+func closingBrace(p string, i int) int {
+	depth := 0
+	for ; i < len(p); i++ {
+		switch p[i] {
+		case '\\':
+			i++
+		case '{':
+			depth++
+		case '}':
+			if depth--; depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// alternatives splits the contents of a pair of braces into the strings they expand to.
+// It returns nil if the braces should not be expanded.
+func alternatives(body string) []string {
+	var alts []string
+	depth, start := 0, 0
+	for i := 0; i < len(body); i++ {
+		switch body[i] {
+		case '\\':
+			i++
+		case '{':
+			depth++
+		case '}':
+			depth--
+		case ',':
+			if depth == 0 {
+				alts = append(alts, body[start:i])
+				start = i + 1
+			}
+		}
+	}
+	if alts != nil {
+		return append(alts, body[start:])
+	}
+	return sequence(body)
+}
+
+var (
+	intSequence  = regexp.MustCompile(`^(-?\d+)\.\.(-?\d+)(?:\.\.(-?\d+))?$`)
+	charSequence = regexp.MustCompile(`^([a-zA-Z])\.\.([a-zA-Z])(?:\.\.(-?\d+))?$`)
+)
+
+// sequence expands the body of a sequence expression, or returns nil if body is not one.
+func sequence(body string) []string {
+	if m := intSequence.FindStringSubmatch(body); m != nil {
+		x, _ := strconv.Atoi(m[1])
+		y, _ := strconv.Atoi(m[2])
+		width := 0
+		if padded(m[1]) || padded(m[2]) {
+			width = len(m[1])
+			if len(m[2]) > width {
+				width = len(m[2])
+			}
+		}
+		var seq []string
+		for _, n := range steps(x, y, m[3]) {
+			// The sign counts toward the width, as in bash.
+			s, digits := strconv.Itoa(n), width
+			if n < 0 {
+				s, digits = s[1:], digits-1
+			}
+			if len(s) < digits {
+				s = strings.Repeat("0", digits-len(s)) + s
+			}
+			if n < 0 {
+				s = "-" + s
+			}
+			seq = append(seq, s)
+		}
+		return seq
+	}
+	if m := charSequence.FindStringSubmatch(body); m != nil {
+		var seq []string
+		for _, n := range steps(int(m[1][0]), int(m[2][0]), m[3]) {
+			seq = append(seq, string(rune(n)))
+		}
+		return seq
+	}
+	return nil
+}
+
+// steps returns the integers from x to y inclusive, taking steps of the size given by incr.
+func steps(x, y int, incr string) []int {
+	step, _ := strconv.Atoi(incr)
+	if step < 0 {
+		step = -step
+	}
+	if step == 0 {
+		step = 1
+	}
+	if y < x {
+		step = -step
+	}
+	var ns []int
+	for n := x; (step > 0 && n <= y) || (step < 0 && n >= y); n += step {
+		ns = append(ns, n)
+	}
+	return ns
+}
+
+// padded reports whether the integer n is written with a leading zero.
+func padded(n string) bool {
+	n = strings.TrimPrefix(n, "-")
+	return len(n) > 1 && n[0] == '0'
+}
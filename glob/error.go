@@ -0,0 +1,28 @@
+package glob
+
+import "strings"
+
+// ErrPath indicates that an error was encountered while globbing at Path.
+type ErrPath struct {
+	Path string
+	Err  error
+}
+
+func (e ErrPath) Error() string {
+	return "Error globbing " + e.Path + ": " + e.Err.Error()
+}
+
+func (e ErrPath) Unwrap() error {
+	return e.Err
+}
+
+// ErrPaths collects every error encountered while globbing permissively.
+type ErrPaths []ErrPath
+
+func (e ErrPaths) Error() string {
+	s := make([]string, len(e))
+	for i, err := range e {
+		s[i] = err.Error()
+	}
+	return strings.Join(s, "\n")
+}
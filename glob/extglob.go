@@ -0,0 +1,144 @@
+package glob
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// extglobQuantifiers maps each extglob operator (other than negation) to its regexp quantifier.
+var extglobQuantifiers = map[byte]string{
+	'?': "?",
+	'*': "*",
+	'+': "+",
+	'@': "",
+}
+
+// group tracks an open extglob while regexifying a pattern.
+type group struct {
+	quantifier  string
+	onseparator bool
+}
+
+// isExtglob reports whether p begins with an extglob operator other than negation.
+func isExtglob(p string) bool {
+	if len(p) < 2 || p[1] != '(' {
+		return false
+	}
+	_, ok := extglobQuantifiers[p[0]]
+	return ok
+}
+
+// matcher reports whether a slash-separated path matches a compiled pattern.
+type matcher interface {
+	MatchString(string) bool
+}
+
+// anyMatcher matches a path that matches any of its matchers.
+type anyMatcher []matcher
+
+func (ms anyMatcher) MatchString(s string) bool {
+	for _, m := range ms {
+		if m.MatchString(s) {
+			return true
+		}
+	}
+	return false
+}
+
+// negation matches a path with a section that matches prefix, followed by a section within one path element that does not match inner,
+// followed by a section that matches suffix.
+type negation struct {
+	prefix, inner *regexp.Regexp
+	suffix        matcher
+	// dot indicates that the negation begins a path element, and so does not match dotfiles.
+	dot bool
+}
+
+func (n *negation) MatchString(s string) bool {
+	for i := 0; i <= len(s); i++ {
+		if !n.prefix.MatchString(s[:i]) {
+			continue
+		}
+		// The element is a dotfile even if the negation matches nothing and the suffix supplies the dot.
+		if n.dot && strings.HasPrefix(s[i:], ".") {
+			continue
+		}
+		for j := i; j <= len(s); j++ {
+			if j > i && s[j-1] == '/' {
+				break
+			}
+			v := s[i:j]
+			if !n.inner.MatchString(v) && n.suffix.MatchString(s[j:]) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// compileSlash creates a matcher for a slash-separated pattern without braces.
+// onseparator indicates whether the pattern begins a path element.
+func compileSlash(p string, onseparator bool, syn syntax) (matcher, error) {
+	start, end, err := negationBounds(p)
+	if err != nil {
+		return nil, err
+	}
+	if start < 0 {
+		return syn.regexp(p, onseparator)
+	}
+	prefix, err := syn.regexp(p[:start], onseparator)
+	if err != nil {
+		return nil, err
+	}
+	seg := onseparator
+	if start > 0 {
+		seg = p[start-1] == '/'
+	}
+	inner, err := syn.regexp("@("+p[start+2:end]+")", seg)
+	if err != nil {
+		return nil, err
+	}
+	suffix, err := compileSlash(p[end+1:], false, syn)
+	if err != nil {
+		return nil, err
+	}
+	return &negation{prefix, inner, suffix, seg && !syn.dot}, nil
+}
+
+// negationBounds returns the indices of the first '!(' in p and its closing ')'.
+// If there is no negation, start is -1.
+// A negation nested within another extglob or lacking a closing ')' is reported as filepath.ErrBadPattern.
+func negationBounds(p string) (start, end int, err error) {
+	start = -1
+	depth := 0
+	for i := 0; i < len(p); i++ {
+		switch {
+		case p[i] == '\\':
+			i++
+		case p[i] == '[':
+			if j := strings.IndexByte(p[i+1:], ']'); j >= 0 {
+				i += j + 1
+			}
+		case strings.HasPrefix(p[i:], "!("):
+			if depth > 0 {
+				return -1, -1, filepath.ErrBadPattern
+			}
+			start = i
+			depth++
+			i++
+		case isExtglob(p[i:]):
+			depth++
+			i++
+		case p[i] == ')' && depth > 0:
+			depth--
+			if depth == 0 && start >= 0 {
+				return start, i, nil
+			}
+		}
+	}
+	if start >= 0 {
+		return -1, -1, filepath.ErrBadPattern
+	}
+	return -1, -1, nil
+}
@@ -0,0 +1,86 @@
+package glob
+
+import (
+	"errors"
+	"io/fs"
+	"path"
+	"strings"
+)
+
+// GlobFS returns the names of files in fsys which match pattern, with the syntax of Match.
+// As with fs.Glob, the pattern and the returned names are slash-separated and relative to the root of fsys.
+// The Options apply as they do to Glob, except for IgnoreFiles, WithIgnore, and Sort,
+// which concern the operating system's filesystem; names are returned in the order of fs.WalkDir.
+//
+// A pattern whose Base is not a valid path for fsys is reported as an ErrPath wrapping fs.ErrInvalid.
+// Errors reading fsys are reported as with Glob.
+func GlobFS(fsys fs.FS, pattern string, opts ...Option) ([]string, error) {
+	p := &Pattern{pattern: pattern, src: pattern}
+	for _, opt := range opts {
+		opt(p)
+	}
+	if p.matchBase && !strings.Contains(pattern, "/") {
+		p.src = "**/" + pattern
+	}
+	var ms anyMatcher
+	for _, e := range Expand(p.src) {
+		m, err := compileSlash(path.Clean(e), true, p.syntax)
+		if err != nil {
+			return nil, err
+		}
+		ms = append(ms, m)
+	}
+	root := strings.TrimSuffix(prefixFS(p.src), "/")
+	if root == "" {
+		root = "."
+	}
+	if !fs.ValidPath(root) {
+		return nil, ErrPath{Path: root, Err: fs.ErrInvalid}
+	}
+	var matches []string
+	var errs ErrPaths
+	err := fs.WalkDir(fsys, root, func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			// A root that does not exist has no matches.
+			if name == root && errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			e := ErrPath{Path: name, Err: err}
+			if !p.permissive {
+				return e
+			}
+			errs = append(errs, e)
+			return nil
+		}
+		if (p.nodir || p.onlydir) && d.IsDir() != p.onlydir {
+			return nil
+		}
+		if ms.MatchString(name) {
+			matches = append(matches, name)
+		}
+		return nil
+	})
+	if err != nil {
+		return matches, err
+	}
+	if len(errs) > 0 {
+		return matches, errs
+	}
+	return matches, nil
+}
+
+// prefixFS is Prefix for slash-separated patterns, excluding SpecialRunes.
+func prefixFS(pattern string) string {
+	sep := 0
+	for i, r := range pattern {
+		for _, s := range SpecialRunes {
+			if r == s {
+				return pattern[:sep]
+			}
+		}
+		if r == '/' {
+			sep = i + 1
+		}
+	}
+	return pattern[:sep]
+}
@@ -0,0 +1,102 @@
+package glob_test
+
+import (
+	"errors"
+	"io/fs"
+	"reflect"
+	"testing"
+	"testing/fstest"
+
+	. "github.com/SaidinWoT/gulf/glob"
+)
+
+var fsys = fstest.MapFS{
+	"a.go":             {},
+	".hidden.go":       {},
+	"src/b.go":         {},
+	"src/b_test.go":    {},
+	"src/c/d.go":       {},
+	"src/c/d.txt":      {},
+	"vendor/e/f.go":    {},
+	"src/.git/HEAD.go": {},
+}
+
+var globsFS = map[string][]string{
+	"*.go":                      {"a.go"},
+	".*.go":                     {".hidden.go"},
+	"src/**/*.go":               {"src/b.go", "src/b_test.go", "src/c/d.go"},
+	"src/c/*.{go,txt}":          {"src/c/d.go", "src/c/d.txt"},
+	"!(vendor)/**/!(*_test).go": {"src/b.go", "src/c/d.go"},
+	"missing/**/*.go":           nil,
+}
+
+func TestGlobFS(t *testing.T) {
+	for pattern, expected := range globsFS {
+		ms, err := GlobFS(fsys, pattern)
+		if err != nil {
+			t.Error(err)
+		}
+		if !reflect.DeepEqual(ms, expected) {
+			t.Errorf(`GlobFS: "%s" produced %q`, pattern, ms)
+		}
+	}
+}
+
+type optionGlobFS struct {
+	pattern  string
+	opts     []Option
+	expected []string
+}
+
+var OptionGlobsFS = []optionGlobFS{
+	{"*.GO", []Option{CaseInsensitive(true)}, []string{"a.go"}},
+	{"*.go", []Option{Dot(true)}, []string{".hidden.go", "a.go"}},
+	{"d.go", []Option{MatchBase(true)}, []string{"src/c/d.go"}},
+	{"src/*", []Option{OnlyDir(true)}, []string{"src/c"}},
+	{"src/*", []Option{NoDir(true)}, []string{"src/b.go", "src/b_test.go"}},
+}
+
+func TestGlobFSOptions(t *testing.T) {
+	for _, test := range OptionGlobsFS {
+		ms, err := GlobFS(fsys, test.pattern, test.opts...)
+		if err != nil {
+			t.Error(err)
+		}
+		if !reflect.DeepEqual(ms, test.expected) {
+			t.Errorf(`GlobFS: "%s" produced %q with options`, test.pattern, ms)
+		}
+	}
+}
+
+// failFS fails to read the directory named fail.
+type failFS struct {
+	fstest.MapFS
+	fail string
+}
+
+func (f failFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	if name == f.fail {
+		return nil, fs.ErrPermission
+	}
+	return f.MapFS.ReadDir(name)
+}
+
+func TestGlobFSErrors(t *testing.T) {
+	if _, err := GlobFS(fsys, "../*.go"); !errors.Is(err, fs.ErrInvalid) {
+		t.Errorf(`GlobFS: "../*.go" reported %v`, err)
+	}
+
+	f := failFS{fsys, "vendor"}
+	_, err := GlobFS(f, "**/*.go")
+	if e, ok := err.(ErrPath); !ok || e.Path != "vendor" || !errors.Is(err, fs.ErrPermission) {
+		t.Errorf("GlobFS: an unreadable directory was reported as %v", err)
+	}
+
+	ms, err := GlobFS(f, "**/*.go", Permissive(true))
+	if errs, ok := err.(ErrPaths); !ok || len(errs) != 1 || errs[0].Path != "vendor" {
+		t.Errorf("GlobFS: permissively globbing an unreadable directory reported %v", err)
+	}
+	if expected := []string{"a.go", "src/b.go", "src/b_test.go", "src/c/d.go"}; !reflect.DeepEqual(ms, expected) {
+		t.Errorf("GlobFS: permissively globbing an unreadable directory produced %q", ms)
+	}
+}
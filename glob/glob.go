@@ -1,20 +1,15 @@
 // Package glob provides an extended globbing system.
-// It suplements path/filepath's globs with globstar, pattern negation, and dotfile filtering.
+// It suplements path/filepath's globs with globstar, braces, extglobs, pattern negation, and dotfile filtering.
 package glob
 
 import (
-	"log"
-	"os"
 	"path/filepath"
 	"regexp"
 	"strconv"
-	"strings"
 )
 
-type includeMap map[string]bool
-
 // SpecialRunes identifies the runes that have special meaning in patterns accepted by Glob.
-var SpecialRunes = []rune("*?")
+var SpecialRunes = []rune("*?{(")
 
 // Match returns true if name matches the provided pattern.
 // The pattern syntax is identical to that of path.Match, except as follows:
@@ -23,98 +18,89 @@ var SpecialRunes = []rune("*?")
 // globstar only has a special meaning if it is the only pattern in its containing element
 // (that is, it is either preceded by the beginning of the string or a filepath separator).
 // Otherwise, it is treated simply as two sequential globs (which will then be condensed to a single glob).
+//	'{a,b}' matches either alternative, as described by Expand.
+//	'?(a|b)', '*(a|b)', '+(a|b)', and '@(a|b)' match zero or one, zero or more, one or more,
+//	or exactly one of the '|'-separated patterns, as with ksh's extglob.
+//	'!(a|b)' matches anything within a path element except the patterns. It may not be nested in another extglob.
+// To match with Options, see MatchFunc or Compile.
 func Match(pattern, name string) (bool, error) {
-	re, err := makeRegexp(pattern)
-	if err != nil {
-		return false, err
-	}
-	abs, err := filepath.Abs(name)
+	p, err := Compile(pattern)
 	if err != nil {
 		return false, err
 	}
-	return re.MatchString(filepath.ToSlash(abs)), nil
+	return p.Match(name), nil
 }
 
 // Glob returns the list of filenames which match the provided pattern.
 // The syntax is the same as for Match.
+// To glob with Options, see GlobFunc or Compile.
 func Glob(pattern string) ([]string, error) {
-	// Lacking a globstar, use filepath.Glob with tedious dotfile filtering
-	if strings.Index(pattern, "**") == -1 {
-		patterns := negateDotfiles(pattern)
-		m, err := include(filepath.Glob, patterns...)
-		return m.list(), err
+	p, err := Compile(pattern)
+	if err != nil {
+		return nil, err
 	}
-	return globstar(pattern)
+	return p.Glob()
 }
 
-// negateDotfiles appends a filter for each glob in pattern that does not explicitly follow a dot character.
-// Each filter will be identical to pattern except for an explicit dot before one of the globs.
-func negateDotfiles(pattern string) []string {
-	patterns := []string{pattern}
-	pieces := strings.Split(pattern, "*")
-	l := len(pieces)
-	for i := 0; i < l-1; i++ {
-		p := pieces[i]
-		if n := len(p); n != 0 && p[n-1] == '.' {
-			continue
-		}
-		ps := make([]string, l)
-		copy(ps, pieces)
-		ps[i] += "."
-		ps[0] = "!" + ps[0]
-		patterns = append(patterns, strings.Join(ps, "*"))
-	}
-	return patterns
+// syntax holds the Options which change how patterns are compiled.
+type syntax struct {
+	fold bool // Match case-insensitively.
+	dot  bool // Match dotfiles with '*' and '**/'.
 }
 
-// globstar does a filesystem walk rooted at the longest definite prefix of pattern.
-// It returns the list of filenames that match the pattern as dictated by the syntax of Match.
-func globstar(pattern string) ([]string, error) {
-	var s []string
-	re, err := makeRegexp(pattern)
-	if err != nil {
-		return s, err
+// regexp compiles a slash-separated pattern without braces or negations into an anchored regular expression.
+func (syn syntax) regexp(p string, onseparator bool) (*regexp.Regexp, error) {
+	re := "^" + regexifyAt(p, onseparator, syn.dot) + "$"
+	if syn.fold {
+		re = "(?i)" + re
 	}
-	filepath.Walk(Prefix(pattern, SpecialRunes), func(path string, info os.FileInfo, _ error) error {
-		if info == nil {
-			log.Println("Malformed directory", path)
-			return filepath.SkipDir
-		}
-		path, err = filepath.Abs(path)
+	return regexp.Compile(re)
+}
+
+// compile creates a matcher for a pattern, resolving relative alternatives against wd.
+// Braces are expanded into alternatives, and extglob negations are split out of the regular expressions.
+// The resulting matcher expects matches to have passed through filepath.ToSlash.
+func compile(pattern, wd string, syn syntax) (matcher, error) {
+	var ms anyMatcher
+	for _, p := range Expand(pattern) {
+		m, err := compileSlash(filepath.ToSlash(abs(wd, p)), true, syn)
 		if err != nil {
-			return nil
-		}
-		if re.MatchString(filepath.ToSlash(path)) {
-			s = append(s, path)
+			return nil, err
 		}
-		return nil
-	})
-	return s, nil
+		ms = append(ms, m)
+	}
+	if len(ms) == 1 {
+		return ms[0], nil
+	}
+	return ms, nil
 }
 
-// makeRegexp compiles a regular expression that will match a filepath containing a globstar.
-// The resulting regex expects matches to have passed through filepath.ToSlash.
-func makeRegexp(p string) (*regexp.Regexp, error) {
-	p, err := filepath.Abs(p)
-	if err != nil {
-		return nil, err
+// abs resolves name against wd, like filepath.Abs without consulting the working directory.
+func abs(wd, name string) string {
+	if filepath.IsAbs(name) {
+		return filepath.Clean(name)
 	}
-	p = filepath.ToSlash(p)
-	p = regexify(p)
-	p = "^" + p + "$"
-	return regexp.Compile(p)
+	return filepath.Join(wd, name)
 }
 
 // regexify converts a pattern string fit for filepath.Match into a pattern for regexp.MatchString.
 // The resulting regexp should have the same results as filepath.Match
 // (with the differences mentioned in the documentation for Match).
 func regexify(p string) string {
+	return regexifyAt(p, true, false)
+}
+
+// regexifyAt is regexify for a pattern which may not begin a path element,
+// as indicated by onseparator.
+// If dot is set, globs and globstars match dotfiles as well.
+func regexifyAt(p string, onseparator, dot bool) string {
 	// The worst case pattern is a cycle of globs followed by a file separator.
-	// This will replace every 2 characters with 16 characters, so 8*len(p) will suffice.
-	s := make([]byte, 8*len(p))
+	// This will replace every 2 characters with 16 characters, so 8*len(p) will suffice
+	// (plus the length of a single glob, for patterns consisting of one).
+	s := make([]byte, 8*len(p)+16)
 	j := 0
-	onseparator := true
 	inrange := false
+	var groups []group
 	l := len(p)
 	for i := 0; i < l; i++ {
 		for inrange && i < l {
@@ -130,6 +116,29 @@ func regexify(p string) string {
 			i++
 			j++
 		}
+		if isExtglob(p[i:]) {
+			groups = append(groups, group{
+				quantifier:  extglobQuantifiers[p[i]],
+				onseparator: onseparator,
+			})
+			j += copy(s[j:], "(?:")
+			i++
+			continue
+		}
+		if n := len(groups); n > 0 {
+			switch p[i] {
+			case '|':
+				s[j] = '|'
+				j++
+				onseparator = groups[n-1].onseparator
+				continue
+			case ')':
+				j += copy(s[j:], ")"+groups[n-1].quantifier)
+				groups = groups[:n-1]
+				onseparator = false
+				continue
+			}
+		}
 		switch p[i] {
 		case '\\':
 			s[j] = p[i]
@@ -138,7 +147,7 @@ func regexify(p string) string {
 		case '.':
 			s[j] = '\\'
 			j++
-			if l > i+1 && p[i+1] == '*' {
+			if l > i+1 && p[i+1] == '*' && !isExtglob(p[i+1:]) {
 				s[j] = '.'
 				i++
 				j++
@@ -148,19 +157,25 @@ func regexify(p string) string {
 			if !onseparator {
 				goto Suffix
 			}
-			if l > i+3 && p[i:i+3] == "**/" {
-				_ = append(s[0:j], []byte("(?:[^./][^/]*/)*")...)
+			// A globstar may end p, as does the section before an extglob negation.
+			if l >= i+3 && p[i:i+3] == "**/" {
+				globstar := "(?:[^./][^/]*/)*"
+				if dot {
+					globstar = "(?:[^/]+/)*"
+				}
+				j += copy(s[j:], globstar)
 				i += 2
 				// Condense sequential globstars
 				for l > i+3 && p[i+1:i+4] == "**/" {
 					i += 3
 				}
-				j += 16
+			} else if dot {
+				goto Suffix
 			} else {
 				_ = append(s[0:j], []byte("(?:[^./][^/]*)?")...)
 				// Condense sequential globs
 				// (?s cannot easily combine with dotfile-excluding globs)
-				for l > i+1 && p[i+1] == '*' {
+				for l > i+1 && p[i+1] == '*' && !isExtglob(p[i+1:]) {
 					i++
 				}
 				j += 15
@@ -174,8 +189,8 @@ func regexify(p string) string {
 			s[j] = '\\'
 			j++
 		}
-    s[j] = p[i]
-    j++
+		s[j] = p[i]
+		j++
 	Separator:
 		onseparator = p[i] == '/'
 		continue
@@ -194,16 +209,15 @@ func regexify(p string) string {
 func wildcardSuffix(p string) (skip int, suffix string) {
 	var req int
 	var star bool
-	for _, b := range p {
-		switch b {
+Loop:
+	for ; skip < len(p) && !isExtglob(p[skip:]); skip++ {
+		switch p[skip] {
 		case '?':
 			req++
-			skip++
 		case '*':
 			star = true
-			skip++
 		default:
-			break
+			break Loop
 		}
 	}
 	if req > 1 {
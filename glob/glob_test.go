@@ -1,6 +1,7 @@
 package glob_test
 
 import (
+	"reflect"
 	"testing"
 
 	. "github.com/SaidinWoT/gulf/glob"
@@ -41,6 +42,47 @@ var Matches = []matches{
 	{"foo/a*.a", "foo/a.a.a", true},
 	{"foo/*.a", "foo/.a", true},
 	{"foo/***.a", "foo/.a", true},
+	{"src/**/*.{js,ts}", "src/a/b.ts", true},
+	{"src/**/*.{js,ts}", "src/a/b.go", false},
+	{"foo/{a,b{c,d}}.a", "foo/bd.a", true},
+	{"foo/{a,b{c,d}}.a", "foo/b.a", false},
+	{"foo/{1..10}.a", "foo/10.a", true},
+	{"foo/\\{a,b}.a", "foo/{a,b}.a", true},
+	{"foo/{a}.a", "foo/{a}.a", true},
+	{"foo/a?b*", "foo/axzz", false},
+	{"foo/a?b*", "foo/axbz", true},
+	{"foo/@(a|b).a", "foo/b.a", true},
+	{"foo/@(a|b).a", "foo/ab.a", false},
+	{"foo/?(a|b).a", "foo/.a", true},
+	{"foo/*(a|b).a", "foo/abba.a", true},
+	{"foo/+(a|b).a", "foo/.a", false},
+	{"foo/+(a|b*).a", "foo/bcd.a", true},
+	{"foo/@(*).a", "foo/.b.a", false},
+	{"foo/a(b|c).a", "foo/a(b|c).a", true},
+	{"foo/!(a|b).a", "foo/c.a", true},
+	{"foo/!(a|b).a", "foo/a.a", false},
+	{"foo/!(a).a", "foo/.b.a", false},
+	{"foo/!(bar)/*.a", "foo/baz/x.a", true},
+	{"foo/!(bar)/*.a", "foo/bar/x.a", false},
+	{"foo/x!(a*)y.a", "foo/xby.a", true},
+	{"foo/x!(a*)y.a", "foo/xaby.a", false},
+	{"**/!(*.go)", "foo/a.txt", true},
+	{"**/!(*.go)", "foo/a.go", false},
+	{"!(vendor)/**/*.go", "src/a/b.go", true},
+	{"!(vendor)/**/*.go", "vendor/a/b.go", false},
+	{"!(vendor)/**/!(*_test).go", "src/b.go", true},
+	{"!(vendor)/**/!(*_test).go", "src/b_test.go", false},
+	{"!(foo)*", ".dot", false},
+	{"!(foo)*", "dot", true},
+	{"src/!(foo)*", "src/.dot", false},
+	{"src/**/!(*_test).go", "src/b.go", true},
+	{"src/**/!(*_test).go", "src/a/b/c.go", true},
+	{"src/**/!(*_test).go", "src/a/c_test.go", false},
+	{"**/!(*_test).go", "b.go", true},
+	{"*", "x", true},
+	{"*", ".x", false},
+	{"?", "x", true},
+	{"**", "x", true},
 }
 
 func TestMatch(t *testing.T) {
@@ -54,3 +96,35 @@ func TestMatch(t *testing.T) {
 		}
 	}
 }
+
+type expansions struct {
+	pattern  string
+	patterns []string
+}
+
+var Expansions = []expansions{
+	{"a", []string{"a"}},
+	{"{a,b}", []string{"a", "b"}},
+	{"x{a,b}y{c,d}", []string{"xayc", "xayd", "xbyc", "xbyd"}},
+	{"{a,b{c,d}}", []string{"a", "bc", "bd"}},
+	{"{a}{b,c}", []string{"{a}b", "{a}c"}},
+	{"{,a}", []string{"", "a"}},
+	{"\\{a,b}", []string{"\\{a,b}"}},
+	{"{a,b", []string{"{a,b"}},
+	{"{1..3}", []string{"1", "2", "3"}},
+	{"{3..1}", []string{"3", "2", "1"}},
+	{"{1..7..3}", []string{"1", "4", "7"}},
+	{"{08..10}", []string{"08", "09", "10"}},
+	{"{-1..01}", []string{"-1", "00", "01"}},
+	{"{a..c}", []string{"a", "b", "c"}},
+	{"{1..a}", []string{"{1..a}"}},
+}
+
+func TestExpand(t *testing.T) {
+	for _, test := range Expansions {
+		ps := Expand(test.pattern)
+		if !reflect.DeepEqual(ps, test.patterns) {
+			t.Errorf(`Expand: "%s" produced %q`, test.pattern, ps)
+		}
+	}
+}
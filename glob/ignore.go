@@ -0,0 +1,202 @@
+package glob
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// DefaultIgnoreFiles are the names of the files read by NewIgnore when none are provided.
+var DefaultIgnoreFiles = []string{".gitignore", ".gulfignore"}
+
+// An Ignore reports whether filenames are excluded by the rules of ignore files, following the semantics of gitignore:
+//	Blank lines and lines beginning with '#' are skipped.
+//	A leading '!' re-includes names excluded by preceding rules, unless one of their parent directories is excluded.
+//	A trailing '/' only matches directories.
+//	A '/' at the beginning or middle anchors the rule to the directory of the ignore file;
+//	otherwise it matches names at any depth beneath it.
+//	'*' and '?' never match '/', while '**/', '/**/', and a trailing '/**' match any number of directories.
+//	A '\' escapes the following character.
+// Ignore files in every directory beneath the root apply to the names within that directory,
+// with rules in deeper files taking precedence.
+//
+// Ignore files are read once, when first needed.
+type Ignore struct {
+	root  string
+	files []string
+
+	mu    sync.Mutex
+	rules map[string][]rule // The rules read from the ignore files of each directory.
+}
+
+// rule is a single line of an ignore file.
+type rule struct {
+	re      *regexp.Regexp // Matches slash-separated names relative to the directory of the ignore file.
+	negated bool
+	dir     bool
+}
+
+// NewIgnore returns an Ignore for names beneath root, which reads the provided ignore files.
+// If no files are provided, DefaultIgnoreFiles are used.
+func NewIgnore(root string, files ...string) *Ignore {
+	if abs, err := filepath.Abs(root); err == nil {
+		root = abs
+	}
+	if len(files) == 0 {
+		files = DefaultIgnoreFiles
+	}
+	return &Ignore{
+		root:  root,
+		files: files,
+		rules: make(map[string][]rule),
+	}
+}
+
+// Match reports whether name is ignored.
+// Names outside of the root are never ignored.
+// Directories are identified with os.Lstat; names that do not exist are treated as files.
+func (ig *Ignore) Match(name string) bool {
+	fi, err := os.Lstat(name)
+	return ig.ignored(name, err == nil && fi.IsDir())
+}
+
+// ignored reports whether name, which is a directory if dir is set, is ignored.
+func (ig *Ignore) ignored(name string, dir bool) bool {
+	abs, err := filepath.Abs(name)
+	if err != nil {
+		return false
+	}
+	rel, err := filepath.Rel(ig.root, abs)
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return false
+	}
+	parts := strings.Split(filepath.ToSlash(rel), "/")
+	// A name within an ignored directory is ignored, no matter the rules for the name itself.
+	for i := range parts {
+		if ig.excluded(parts[:i+1], dir || i < len(parts)-1) {
+			return true
+		}
+	}
+	return false
+}
+
+// excluded reports whether the last rule matching the path made of parts excludes it.
+func (ig *Ignore) excluded(parts []string, dir bool) bool {
+	path := strings.Join(parts, "/")
+	var excluded bool
+	for i := range parts {
+		base := strings.Join(parts[:i], "/")
+		for _, r := range ig.read(base) {
+			if r.dir && !dir {
+				continue
+			}
+			rel := path
+			if base != "" {
+				rel = path[len(base)+1:]
+			}
+			if r.re.MatchString(rel) {
+				excluded = !r.negated
+			}
+		}
+	}
+	return excluded
+}
+
+// read returns the rules of the ignore files in base, reading them if necessary.
+func (ig *Ignore) read(base string) []rule {
+	ig.mu.Lock()
+	defer ig.mu.Unlock()
+	if rs, ok := ig.rules[base]; ok {
+		return rs
+	}
+	var rs []rule
+	for _, name := range ig.files {
+		f, err := os.Open(filepath.Join(ig.root, filepath.FromSlash(base), name))
+		if err != nil {
+			continue
+		}
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			if r, ok := parseRule(scanner.Text()); ok {
+				rs = append(rs, r)
+			}
+		}
+		f.Close()
+	}
+	ig.rules[base] = rs
+	return rs
+}
+
+// parseRule parses a line of an ignore file, reporting false if it contains no rule.
+func parseRule(line string) (rule, bool) {
+	var r rule
+	// Trailing spaces are trimmed unless escaped.
+	for strings.HasSuffix(line, " ") && !strings.HasSuffix(line, "\\ ") {
+		line = line[:len(line)-1]
+	}
+	if line == "" || line[0] == '#' {
+		return r, false
+	}
+	if line[0] == '!' {
+		r.negated = true
+		line = line[1:]
+	}
+	if strings.HasSuffix(line, "/") {
+		r.dir = true
+		line = strings.TrimRight(line, "/")
+	}
+	if line == "" {
+		return r, false
+	}
+	anchored := strings.Contains(line, "/")
+	line = strings.TrimPrefix(line, "/")
+	if !anchored {
+		line = "**/" + line
+	}
+	re, err := regexp.Compile("^" + ignoreRegexp(line) + "$")
+	if err != nil {
+		return r, false
+	}
+	r.re = re
+	return r, true
+}
+
+// ignoreRegexp converts a gitignore pattern into a regular expression.
+func ignoreRegexp(p string) string {
+	var b strings.Builder
+	for i := 0; i < len(p); i++ {
+		switch {
+		case strings.HasPrefix(p[i:], "**/") && (i == 0 || p[i-1] == '/'):
+			b.WriteString("(?:.*/)?")
+			i += 2
+		case p[i:] == "**" && i > 0 && p[i-1] == '/':
+			b.WriteString(".*")
+			i++
+		case p[i] == '*':
+			b.WriteString("[^/]*")
+		case p[i] == '?':
+			b.WriteString("[^/]")
+		case p[i] == '\\' && i+1 < len(p):
+			i++
+			b.WriteString(regexp.QuoteMeta(p[i : i+1]))
+		case p[i] == '[':
+			j := strings.IndexByte(p[i+1:], ']')
+			if j < 0 {
+				b.WriteString(regexp.QuoteMeta(p[i:]))
+				return b.String()
+			}
+			class := p[i+1 : i+1+j]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			b.WriteString("[" + class + "]")
+			i += j + 1
+		default:
+			b.WriteString(regexp.QuoteMeta(p[i : i+1]))
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,51 @@
+package glob_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	. "github.com/SaidinWoT/gulf/glob"
+)
+
+var ignoreFiles = map[string]string{
+	".gitignore":     "# comment\n*.log\n!keep.log\n/build/\ndocs/*.tmp\ncache/\n\\#hash\n",
+	".gulfignore":    "**/gen/**\n",
+	"src/.gitignore": "*.o\n!main.o\n/local\n",
+}
+
+var ignores = map[string]bool{
+	"a.log":          true,
+	"src/deep/a.log": true,
+	"keep.log":       false,
+	"build":          true,
+	"build/x.go":     true,
+	"src/build/x.go": false,
+	"docs/a.tmp":     true,
+	"docs/sub/a.tmp": false,
+	"src/cache/x.go": true,
+	"cache":          true,
+	"#hash":          true,
+	"src/a.o":        true,
+	"src/main.o":     false,
+	"a.o":            false,
+	"src/local":      true,
+	"src/deep/local": false,
+	"x/gen/y/z.go":   true,
+	"x/gen":          false,
+	"src/main.go":    false,
+	"cache/keep.log": true,
+	"../outside.log": false,
+}
+
+func TestIgnore(t *testing.T) {
+	root := tempDir(t, "build/", "cache/", "src/cache/")
+	for name, content := range ignoreFiles {
+		writeFile(root, name, content)
+	}
+	ig := NewIgnore(root)
+	for name, ignored := range ignores {
+		if b := ig.Match(filepath.Join(root, name)); b != ignored {
+			t.Errorf(`Ignore: "%s" ignored was reported as %t`, name, b)
+		}
+	}
+}
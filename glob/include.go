@@ -1,40 +1,81 @@
 package glob
 
 import (
-	"log"
 	"path/filepath"
 )
 
+// includeMap tracks the files included by a sequence of patterns, in the order they were first included.
+type includeMap struct {
+	order    []string
+	included map[string]int // The index in order of each included file.
+}
+
 // include constructs a map of files to be included by running the set of patterns through the provided globFn.
 // Filepaths are made absolute and cleaned to eliminate most possible duplications.
+// Files are ordered by the pattern which included them, and then by the order returned by globFn.
+// A file excluded by a negation and included again by a later pattern takes the later position.
+//
+// Files returned by globFn along with an error are still included.
+// ErrPaths are collected, and returned once every pattern has been processed;
+// any other error stops at the pattern which returned it.
 func include(globFn func(string) ([]string, error), patterns ...string) (includeMap, error) {
-	m := make(map[string]bool)
+	m := newIncludeMap()
+	var errs ErrPaths
 	for _, pattern := range patterns {
-		include := true
-		if pattern[0] == '!' {
-			pattern = pattern[1:]
-			include = false
-		}
+		pattern, negated := Negation(pattern)
 		p, err := globFn(pattern)
-		if err != nil {
+		if err := m.merge(p, negated, err, &errs); err != nil {
 			return m, err
 		}
-		for _, s := range p {
-			s, err := filepath.Abs(s)
-			if err != nil {
-				log.Println(err)
+	}
+	if len(errs) > 0 {
+		return m, errs
+	}
+	return m, nil
+}
+
+func newIncludeMap() includeMap {
+	return includeMap{included: make(map[string]int)}
+}
+
+// merge adds the files matched by a pattern, which were returned along with err.
+// ErrPaths are appended to errs, so that globbing continues; any other error is returned.
+func (m *includeMap) merge(files []string, negated bool, err error, errs *ErrPaths) error {
+	if err := m.add(files, negated); err != nil {
+		return err
+	}
+	if e, ok := err.(ErrPaths); ok {
+		*errs = append(*errs, e...)
+		return nil
+	}
+	return err
+}
+
+// add includes the files matched by a pattern, or excludes them if it is negated.
+func (m *includeMap) add(files []string, negated bool) error {
+	for _, s := range files {
+		abs, err := filepath.Abs(s)
+		if err != nil {
+			return ErrPath{Path: s, Err: err}
+		}
+		abs = filepath.Clean(abs)
+		if _, ok := m.included[abs]; ok == negated {
+			if negated {
+				delete(m.included, abs)
+			} else {
+				m.included[abs] = len(m.order)
+				m.order = append(m.order, abs)
 			}
-			s = filepath.Clean(s)
-			m[s] = include
 		}
 	}
-	return m, nil
+	return nil
 }
 
+// list returns the included files in order.
 func (m includeMap) list() []string {
 	var fs []string
-	for s, include := range m {
-		if include {
+	for i, s := range m.order {
+		if j, ok := m.included[s]; ok && i == j {
 			fs = append(fs, s)
 		}
 	}
@@ -0,0 +1,96 @@
+package glob
+
+// The Option type is a function that modifies how a Pattern matches and globs.
+type Option func(*Pattern)
+
+// IgnoreFiles returns an Option that excludes names ignored by the provided ignore files
+// (DefaultIgnoreFiles, if none are provided) beneath the working directory, as with NewIgnore.
+func IgnoreFiles(files ...string) Option {
+	return WithIgnore(NewIgnore(".", files...))
+}
+
+// WithIgnore returns an Option that excludes names ignored by ig.
+func WithIgnore(ig *Ignore) Option {
+	return func(p *Pattern) {
+		p.ignore = ig
+	}
+}
+
+// Sort returns an Option that sets the Order of the matches returned by Glob.
+//
+// The default is Lexical
+func Sort(o Order) Option {
+	return func(p *Pattern) {
+		p.order = o
+	}
+}
+
+// Permissive returns an Option that sets whether globbing continues after encountering errors,
+// such as unreadable directories.
+// If so, every error is collected into an ErrPaths, which is returned along with all of the matches found.
+// Otherwise, globbing stops at the first error, which is returned as an ErrPath.
+func Permissive(on bool) Option {
+	return func(p *Pattern) {
+		p.permissive = on
+	}
+}
+
+// CaseInsensitive returns an Option that sets whether patterns match regardless of case.
+// When globbing, the Base of the pattern is still read from the filesystem as written.
+func CaseInsensitive(on bool) Option {
+	return func(p *Pattern) {
+		p.syntax.fold = on
+	}
+}
+
+// Dot returns an Option that sets whether '*' and '**/' match dotfiles and dot directories
+// without an explicit dot.
+func Dot(on bool) Option {
+	return func(p *Pattern) {
+		p.syntax.dot = on
+	}
+}
+
+// MatchBase returns an Option that sets whether patterns without a file separator
+// match base names in any directory, as though preceded by '**/'.
+func MatchBase(on bool) Option {
+	return func(p *Pattern) {
+		p.matchBase = on
+	}
+}
+
+// NoDir returns an Option that sets whether Glob excludes directories from its matches.
+func NoDir(on bool) Option {
+	return func(p *Pattern) {
+		p.nodir = on
+	}
+}
+
+// OnlyDir returns an Option that sets whether Glob only includes directories in its matches.
+func OnlyDir(on bool) Option {
+	return func(p *Pattern) {
+		p.onlydir = on
+	}
+}
+
+// GlobFunc returns a globbing function like Glob, which compiles each pattern with opts.
+func GlobFunc(opts ...Option) func(string) ([]string, error) {
+	return func(pattern string) ([]string, error) {
+		p, err := Compile(pattern, opts...)
+		if err != nil {
+			return nil, err
+		}
+		return p.Glob()
+	}
+}
+
+// MatchFunc returns a matching function like Match, which compiles each pattern with opts.
+func MatchFunc(opts ...Option) func(string, string) (bool, error) {
+	return func(pattern, name string) (bool, error) {
+		p, err := Compile(pattern, opts...)
+		if err != nil {
+			return false, err
+		}
+		return p.Match(name), nil
+	}
+}
@@ -0,0 +1,83 @@
+package glob_test
+
+import (
+	"reflect"
+	"testing"
+
+	. "github.com/SaidinWoT/gulf/glob"
+)
+
+type optionMatches struct {
+	opts          []Option
+	pattern, name string
+	result        bool
+}
+
+var OptionMatches = []optionMatches{
+	{[]Option{CaseInsensitive(true)}, "foo/*.GO", "foo/a.go", true},
+	{[]Option{CaseInsensitive(false)}, "foo/*.GO", "foo/a.go", false},
+	{[]Option{CaseInsensitive(true)}, "FOO/!(A).go", "foo/a.go", false},
+	{[]Option{Dot(true)}, "foo/*.a", "foo/.b.a", true},
+	{[]Option{Dot(true)}, "**/*.a", ".foo/.git/b.a", true},
+	{[]Option{Dot(false)}, "**/*.a", ".foo/b.a", false},
+	{[]Option{Dot(true)}, "foo/!(b).a", "foo/.c.a", true},
+	{[]Option{Dot(true)}, "!(foo)*", ".dot", true},
+	{[]Option{MatchBase(true)}, "*.a", "foo/bar/b.a", true},
+	{[]Option{MatchBase(true)}, "bar/*.a", "foo/bar/b.a", false},
+	{[]Option{MatchBase(false)}, "*.a", "foo/bar/b.a", false},
+}
+
+func TestMatchFunc(t *testing.T) {
+	for _, test := range OptionMatches {
+		b, err := MatchFunc(test.opts...)(test.pattern, test.name)
+		if err != nil {
+			t.Error(err)
+		}
+		if b != test.result {
+			t.Errorf(`MatchFunc: "%s" matches "%s" was reported as %t`, test.pattern, test.name, b)
+		}
+	}
+}
+
+func TestCompileSetOptions(t *testing.T) {
+	for _, test := range OptionMatches {
+		s, err := CompileSet([]string{test.pattern}, test.opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if b := s.Match(test.name); b != test.result {
+			t.Errorf(`CompileSet: "%s" matches "%s" was reported as %t`, test.pattern, test.name, b)
+		}
+	}
+}
+
+var optionFiles = []string{"a.go", "B.GO", ".c.go", "d/e.go", "d/.f/g.go"}
+
+type optionGlobs struct {
+	opts    []Option
+	pattern string
+	names   []string
+}
+
+var OptionGlobs = []optionGlobs{
+	{nil, "*.go", []string{"a.go"}},
+	{[]Option{CaseInsensitive(true)}, "*.go", []string{"B.GO", "a.go"}},
+	{[]Option{Dot(true)}, "*.go", []string{".c.go", "a.go"}},
+	{[]Option{Dot(true)}, "**/*.go", []string{".c.go", "a.go", "d/.f/g.go", "d/e.go"}},
+	{[]Option{MatchBase(true)}, "*.go", []string{"a.go", "d/e.go"}},
+	{[]Option{NoDir(true)}, "*", []string{"B.GO", "a.go"}},
+	{[]Option{OnlyDir(true)}, "*", []string{"d"}},
+}
+
+func TestGlobFunc(t *testing.T) {
+	chdir(t, tempDir(t, optionFiles...))
+	for _, test := range OptionGlobs {
+		ms, err := Parse(GlobFunc(test.opts...), test.pattern)
+		if err != nil {
+			t.Error(err)
+		}
+		if !reflect.DeepEqual(ms, test.names) {
+			t.Errorf(`GlobFunc: "%s" produced %q`, test.pattern, ms)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package glob
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Order determines how Glob sorts its matches.
+type Order int
+
+const (
+	// Lexical sorts matches bytewise, as sort.Strings does.
+	Lexical Order = iota
+	// Natural sorts matches like Lexical, except that runs of digits are compared numerically,
+	// so that "file2" precedes "file10".
+	Natural
+	// Depth sorts matches in shallower directories before those in deeper ones, then lexically.
+	Depth
+)
+
+// sort sorts names in place.
+func (o Order) sort(names []string) {
+	switch o {
+	case Natural:
+		sort.Slice(names, func(i, j int) bool {
+			return naturalLess(names[i], names[j])
+		})
+	case Depth:
+		sort.Slice(names, func(i, j int) bool {
+			di := strings.Count(names[i], string(filepath.Separator))
+			dj := strings.Count(names[j], string(filepath.Separator))
+			if di != dj {
+				return di < dj
+			}
+			return names[i] < names[j]
+		})
+	default:
+		sort.Strings(names)
+	}
+}
+
+// naturalLess reports whether a precedes b, comparing runs of digits by their numeric value.
+func naturalLess(a, b string) bool {
+	for a != "" && b != "" {
+		da, db := digits(a), digits(b)
+		if da == 0 || db == 0 {
+			if a[0] != b[0] {
+				return a[0] < b[0]
+			}
+			a, b = a[1:], b[1:]
+			continue
+		}
+		// Compare the runs without leading zeros by length, then bytewise.
+		na, nb := strings.TrimLeft(a[:da], "0"), strings.TrimLeft(b[:db], "0")
+		if len(na) != len(nb) {
+			return len(na) < len(nb)
+		}
+		if na != nb {
+			return na < nb
+		}
+		// Equal values with fewer leading zeros sort first.
+		if da != db {
+			return da < db
+		}
+		a, b = a[da:], b[db:]
+	}
+	return len(a) < len(b)
+}
+
+// digits returns the length of the run of ASCII digits at the beginning of s.
+func digits(s string) int {
+	n := 0
+	for n < len(s) && '0' <= s[n] && s[n] <= '9' {
+		n++
+	}
+	return n
+}
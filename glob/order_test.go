@@ -0,0 +1,48 @@
+package glob_test
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	. "github.com/SaidinWoT/gulf/glob"
+)
+
+var orderFiles = []string{"f10.txt", "f2.txt", "f02.txt", "a/f1.txt", "b.md"}
+
+var orders = map[Order][]string{
+	Lexical: {"a/f1.txt", "f02.txt", "f10.txt", "f2.txt"},
+	Natural: {"a/f1.txt", "f2.txt", "f02.txt", "f10.txt"},
+	Depth:   {"f02.txt", "f10.txt", "f2.txt", "a/f1.txt"},
+}
+
+func TestSort(t *testing.T) {
+	root := tempDir(t, orderFiles...)
+	for order, names := range orders {
+		expected := make([]string, len(names))
+		for i, name := range names {
+			expected[i] = filepath.Join(root, name)
+		}
+		ms, err := GlobFunc(Sort(order))(filepath.Join(root, "**/*.txt"))
+		if err != nil {
+			t.Error(err)
+		}
+		if !reflect.DeepEqual(ms, expected) {
+			t.Errorf("Sort: %d produced %q", order, ms)
+		}
+	}
+}
+
+func TestParseOrder(t *testing.T) {
+	chdir(t, tempDir(t, orderFiles...))
+	for i := 0; i < 5; i++ {
+		ms, err := Parse(Glob, "*.md", "**/*.txt", "!f2.txt", "f2.txt")
+		if err != nil {
+			t.Error(err)
+		}
+		expected := []string{"b.md", "a/f1.txt", "f02.txt", "f10.txt", "f2.txt"}
+		if !reflect.DeepEqual(ms, expected) {
+			t.Fatalf("Parse: produced %q", ms)
+		}
+	}
+}
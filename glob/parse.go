@@ -3,6 +3,7 @@ package glob
 import (
 	"os"
 	"path/filepath"
+	"strings"
 )
 
 // Ancestor returns the closest common ancestor of the provided paths, with the trailing file separator.
@@ -59,20 +60,49 @@ func prefixer(path string, rs [][]rune, fn func(rune, []rune, int) bool) string
 	return path[:sep]
 }
 
+// Negation reports whether pattern is a negation (that is, begins with '!'),
+// and returns the pattern with the '!' removed.
+// A pattern beginning with the extglob '!(' is not a negation.
+func Negation(pattern string) (string, bool) {
+	if len(pattern) > 0 && pattern[0] == '!' && !strings.HasPrefix(pattern, "!(") {
+		return pattern[1:], true
+	}
+	return pattern, false
+}
+
 // Parse returns the full list of file and directory names matched by
 // the provided globbing function with the list of patterns.
 // A pattern with '!' as its first character is treated as a negation.
 // Patterns are processed in sequential order - negations will remove
 // matches from preceding patterns, but not those which follow them.
-func Parse(globFn func(string) ([]string, error), patterns ...string) []string {
-	wd, err := os.Getwd()
-	if err != nil {
-		return nil
-	}
+// Names are ordered by the pattern which first matched them, and then by the order returned by globFn,
+// so that the results are deterministic.
+// To test individual names against patterns without walking the filesystem, see PatternSet;
+// to reuse compiled patterns, see PatternSet.Glob.
+//
+// The names are relative to the working directory.
+// Names returned by globFn along with an error are still included.
+// ErrPaths, such as those of a Permissive globbing function, are collected from every pattern and returned together;
+// any other error from globFn is returned along with the names matched up to it.
+func Parse(globFn func(string) ([]string, error), patterns ...string) ([]string, error) {
 	m, err := include(globFn, patterns...)
+	return relative(m, err)
+}
+
+// relative returns the list of names included by m relative to the working directory,
+// along with err.
+func relative(m includeMap, err error) ([]string, error) {
+	wd, wdErr := os.Getwd()
+	if wdErr != nil {
+		return nil, wdErr
+	}
 	l := m.list()
 	for i, s := range l {
-		l[i], _ = filepath.Rel(wd, s)
+		rel, relErr := filepath.Rel(wd, s)
+		if relErr != nil {
+			return nil, ErrPath{Path: s, Err: relErr}
+		}
+		l[i] = rel
 	}
-	return l
+	return l, err
 }
@@ -1,6 +1,8 @@
 package glob_test
 
 import (
+	"path/filepath"
+	"reflect"
 	"testing"
 
 	. "github.com/SaidinWoT/gulf/glob"
@@ -54,3 +56,41 @@ func TestPrefix(t *testing.T) {
 		}
 	}
 }
+
+func TestNegation(t *testing.T) {
+	for pattern, expected := range map[string]bool{"!*.go": true, "*.go": false, "": false, "!": true, "!(a|b).go": false} {
+		if _, negated := Negation(pattern); negated != expected {
+			t.Errorf(`Negation: "%s" reported %t`, pattern, negated)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	root := tempDir(t, "a/x.go", "b/y.go", "c/z.go", "r.md")
+	chdir(t, root)
+	locked := lock(t, filepath.Join(root, "b"))
+	patterns := []string{"**/*.go", "*/*.go", "*.md"}
+
+	// Matches found before and after each error are kept, and every error is reported.
+	ms, err := Parse(GlobFunc(Permissive(true)), patterns...)
+	if e, ok := err.(ErrPaths); !ok || len(e) != 2 || e[0].Path != locked || e[1].Path != locked {
+		t.Errorf("Parse: reported %v rather than ErrPaths for %s", err, locked)
+	}
+	expected := []string{"a/x.go", "c/z.go", "r.md"}
+	if !reflect.DeepEqual(ms, expected) {
+		t.Errorf("Parse: permissively produced %q", ms)
+	}
+
+	s, err := CompileSet([]string{"*.md", "**/*.go"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Without Permissive, globbing stops at the first error, keeping the matches of preceding patterns.
+	ms, err = s.Glob()
+	if e, ok := err.(ErrPath); !ok || e.Path != locked {
+		t.Errorf("PatternSet.Glob: reported %v rather than an ErrPath for %s", err, locked)
+	}
+	if len(ms) == 0 || ms[0] != "r.md" {
+		t.Errorf("PatternSet.Glob: produced %q", ms)
+	}
+}
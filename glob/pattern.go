@@ -0,0 +1,88 @@
+package glob
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// A Pattern is a compiled pattern, as accepted by Match and Glob.
+// Relative patterns are resolved against the working directory at the time of compilation.
+type Pattern struct {
+	pattern string
+	src     string // The pattern after applying MatchBase.
+	wd      string // The working directory at the time of compilation.
+	m       matcher
+	syntax  syntax
+	ignore  *Ignore
+	order   Order
+	// permissive collects errors while globbing, rather than stopping at the first.
+	permissive bool
+	matchBase  bool
+	nodir      bool
+	onlydir    bool
+}
+
+// Compile parses a pattern and returns, if successful, a Pattern that can be used to match against filenames.
+func Compile(pattern string, opts ...Option) (*Pattern, error) {
+	// The working directory is only needed to resolve relative patterns and names.
+	wd, err := os.Getwd()
+	if err != nil && !filepath.IsAbs(pattern) {
+		return nil, err
+	}
+	p := &Pattern{pattern: pattern, src: pattern, wd: wd}
+	for _, opt := range opts {
+		opt(p)
+	}
+	if p.matchBase && !strings.ContainsAny(pattern, "/"+string(filepath.Separator)) {
+		p.src = "**/" + pattern
+	}
+	m, err := compile(p.src, wd, p.syntax)
+	if err != nil {
+		return nil, err
+	}
+	p.m = m
+	return p, nil
+}
+
+// String returns the source text used to compile the pattern.
+func (p *Pattern) String() string {
+	return p.pattern
+}
+
+// Base returns the longest definite prefix of the pattern - all matches lie beneath it,
+// unless braces expand to alternatives outside of it, as in "{.,../other}/*.go".
+// As with Prefix, it is either empty or ends with a file separator.
+func (p *Pattern) Base() string {
+	return Prefix(p.src, SpecialRunes)
+}
+
+// Match reports whether name matches the pattern and is not ignored.
+// Relative names are resolved against the same working directory as the pattern.
+// The NoDir and OnlyDir Options do not apply to Match.
+func (p *Pattern) Match(name string) bool {
+	name = abs(p.wd, name)
+	if !p.m.MatchString(filepath.ToSlash(name)) {
+		return false
+	}
+	return p.ignore == nil || !p.ignore.Match(name)
+}
+
+// Glob returns the list of filenames which match the pattern and are not ignored,
+// sorted by the Pattern's Order.
+// Errors reading directories are reported as described by Permissive.
+func (p *Pattern) Glob() ([]string, error) {
+	ms, err := p.walk()
+	matches := ms[:0]
+	for _, m := range ms {
+		if p.nodir || p.onlydir {
+			fi, err := os.Stat(m)
+			if err != nil || fi.IsDir() != p.onlydir {
+				continue
+			}
+		}
+		matches = append(matches, m)
+	}
+	p.order.sort(matches)
+	return matches, err
+}
@@ -0,0 +1,122 @@
+package glob_test
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	. "github.com/SaidinWoT/gulf/glob"
+)
+
+func TestCompile(t *testing.T) {
+	for _, test := range Matches {
+		p, err := Compile(test.pattern)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if p.String() != test.pattern {
+			t.Errorf(`Compile: "%s" reported its pattern as "%s"`, test.pattern, p)
+		}
+		if b := p.Match(test.name); b != test.result {
+			t.Errorf(`Compile: "%s" matches "%s" was reported as %t`, test.pattern, test.name, b)
+		}
+	}
+}
+
+func TestBase(t *testing.T) {
+	for pattern, base := range map[string]string{
+		"src/**/*.go":      "src/",
+		"src/a/{b,c}/*.go": "src/a/",
+		"src/a/b.go":       "src/a/",
+		"!(vendor)/*.go":   "",
+	} {
+		p, err := Compile(pattern)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if b := p.Base(); b != base {
+			t.Errorf(`Base: "%s" != "%s"`, b, base)
+		}
+	}
+}
+
+type setMatches struct {
+	patterns []string
+	name     string
+	result   bool
+}
+
+var SetMatches = []setMatches{
+	{[]string{"src/**/*.go"}, "src/a/b.go", true},
+	{[]string{"src/**/*.go", "!src/vendor/**/*"}, "src/vendor/a/b.go", false},
+	{[]string{"src/**/*.go", "!src/vendor/**/*", "src/vendor/keep/*.go"}, "src/vendor/keep/b.go", true},
+	{[]string{"!src/vendor/**/*", "src/**/*.go"}, "src/vendor/a/b.go", true},
+	{[]string{"!(vendor)/*.go"}, "src/b.go", true},
+	{[]string{}, "src/b.go", false},
+}
+
+func TestCompileSet(t *testing.T) {
+	for _, test := range SetMatches {
+		s, err := CompileSet(test.patterns)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if b := s.Match(test.name); b != test.result {
+			t.Errorf(`CompileSet: %q matches "%s" was reported as %t`, test.patterns, test.name, b)
+		}
+		if ps := s.Patterns(); !reflect.DeepEqual(ps, test.patterns) {
+			t.Errorf(`CompileSet: %q reported its patterns as %q`, test.patterns, ps)
+		}
+	}
+}
+
+func TestMatchWorkingDirectory(t *testing.T) {
+	root := tempDir(t, "src/")
+	chdir(t, root)
+	p, err := Compile("*.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.Chdir("src")
+	if !p.Match("a.go") {
+		t.Error(`Match: "a.go" was not resolved against the working directory at compilation`)
+	}
+	if p.Match(filepath.Join(root, "src", "a.go")) {
+		t.Error(`Match: "*.go" matched a file beneath src after changing directory`)
+	}
+}
+
+func TestCompileWithoutWorkingDirectory(t *testing.T) {
+	root := tempDir(t, "a.go", "gone/")
+	gone := filepath.Join(root, "gone")
+	chdir(t, gone)
+	os.Remove(gone)
+	if _, err := os.Getwd(); err == nil {
+		t.Skip("The removed working directory is still reported")
+	}
+	ms, err := Glob(filepath.Join(root, "*.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := []string{filepath.Join(root, "a.go")}; !reflect.DeepEqual(ms, expected) {
+		t.Errorf("Glob: produced %q without a working directory", ms)
+	}
+}
+
+func TestPatternSetGlob(t *testing.T) {
+	chdir(t, tempDir(t, orderFiles...))
+	patterns := []string{"*.md", "**/*.txt", "!f2.txt", "f2.txt"}
+	s, err := CompileSet(patterns)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ms, err := s.Glob()
+	if err != nil {
+		t.Error(err)
+	}
+	expected, _ := Parse(Glob, patterns...)
+	if !reflect.DeepEqual(ms, expected) {
+		t.Errorf("PatternSet.Glob: produced %q, Parse produced %q", ms, expected)
+	}
+}
@@ -0,0 +1,75 @@
+package glob
+
+// A PatternSet is an ordered list of compiled patterns, some of which may be negations.
+// It reports whether a filename is included by the patterns without touching the filesystem.
+//
+// As with Parse, patterns are processed in sequential order - negations exclude
+// matches of preceding patterns, but not those which follow them.
+type PatternSet struct {
+	patterns []*Pattern
+	negated  []bool
+}
+
+// CompileSet compiles each of patterns, returning a PatternSet if all of them are valid.
+// A pattern with '!' as its first character is treated as a negation.
+// Any opts are applied to every pattern, as with Compile.
+func CompileSet(patterns []string, opts ...Option) (*PatternSet, error) {
+	s := &PatternSet{
+		patterns: make([]*Pattern, len(patterns)),
+		negated:  make([]bool, len(patterns)),
+	}
+	for i, pattern := range patterns {
+		pattern, negated := Negation(pattern)
+		p, err := Compile(pattern, opts...)
+		if err != nil {
+			return nil, err
+		}
+		s.patterns[i] = p
+		s.negated[i] = negated
+	}
+	return s, nil
+}
+
+// Match reports whether name is included by the set.
+func (s *PatternSet) Match(name string) bool {
+	var included bool
+	for i, p := range s.patterns {
+		// Only patterns which could change the outcome need to be matched.
+		if included != s.negated[i] {
+			continue
+		}
+		if p.Match(name) {
+			included = !s.negated[i]
+		}
+	}
+	return included
+}
+
+// Glob is like Parse with Glob as the globbing function, but uses the compiled patterns of the set.
+// The names are relative to the working directory, and errors are reported as with Parse.
+func (s *PatternSet) Glob() ([]string, error) {
+	m := newIncludeMap()
+	var errs ErrPaths
+	for i, p := range s.patterns {
+		files, err := p.Glob()
+		if err := m.merge(files, s.negated[i], err, &errs); err != nil {
+			return relative(m, err)
+		}
+	}
+	if len(errs) > 0 {
+		return relative(m, errs)
+	}
+	return relative(m, nil)
+}
+
+// Patterns returns the source text of the patterns in the set, including any leading '!'.
+func (s *PatternSet) Patterns() []string {
+	ps := make([]string, len(s.patterns))
+	for i, p := range s.patterns {
+		ps[i] = p.String()
+		if s.negated[i] {
+			ps[i] = "!" + ps[i]
+		}
+	}
+	return ps
+}
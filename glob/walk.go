@@ -0,0 +1,280 @@
+package glob
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// segment is a single path element of a pattern.
+type segment struct {
+	globstar bool
+	m        matcher
+}
+
+// state is a position in the segments of one of a pattern's brace expansions.
+// A negative index indicates an expansion whose segments could not be determined, which never prunes.
+type state struct {
+	e, i int
+}
+
+// walker searches a directory tree concurrently for the matches of a Pattern.
+// It tracks the segments each directory has matched to prune directories which cannot contain a match.
+type walker struct {
+	m      matcher
+	ignore *Ignore
+	dot    bool        // Whether globstars match dot directories.
+	segs   [][]segment // The segments of each expansion, relative to the root of the walk.
+	// permissive continues walking after errors, rather than stopping at the first.
+	permissive bool
+
+	sem     chan struct{}
+	wg      sync.WaitGroup
+	mu      sync.Mutex
+	matches []string
+	errs    ErrPaths
+}
+
+// walk does a filesystem walk rooted at the common ancestor of the prefixes of the pattern's expansions.
+// It returns the list of filenames that match the pattern as dictated by the syntax of Match.
+// A root that does not exist has no matches.
+//
+// Unless the Pattern is permissive, the walk stops at the first error, which is returned as an ErrPath.
+// Otherwise, every error is collected into an ErrPaths.
+func (p *Pattern) walk() ([]string, error) {
+	expansions := Expand(p.src)
+	root := p.root(expansions)
+	fi, err := os.Stat(root)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, ErrPath{Path: root, Err: err}
+	}
+	w := &walker{
+		m:          p.m,
+		ignore:     p.ignore,
+		dot:        p.syntax.dot,
+		permissive: p.permissive,
+		sem:        make(chan struct{}, runtime.NumCPU()),
+	}
+	var states []state
+	for e, exp := range expansions {
+		w.segs = append(w.segs, compileSegments(exp, root, p.wd, p.syntax))
+		states = w.add(states, state{e, 0})
+	}
+	if w.m.MatchString(filepath.ToSlash(root)) {
+		w.matches = append(w.matches, root)
+	}
+	if fi.IsDir() {
+		real, err := filepath.EvalSymlinks(root)
+		if err != nil {
+			real = root
+		}
+		w.wg.Add(1)
+		w.dir(root, real, []string{real}, states)
+	}
+	w.wg.Wait()
+	switch {
+	case len(w.errs) == 0:
+		return w.matches, nil
+	case !w.permissive:
+		return w.matches, w.errs[0]
+	}
+	return w.matches, w.errs
+}
+
+// root returns the directory to walk for the expansions of the pattern:
+// the closest common ancestor of their prefixes, which may lie outside of the pattern's Base,
+// as the prefixes of "{.,../other}/*.go" do.
+func (p *Pattern) root(expansions []string) string {
+	sep := string(filepath.Separator)
+	bases := make([]string, len(expansions))
+	for i, e := range expansions {
+		bases[i] = abs(p.wd, Prefix(e, SpecialRunes))
+		if !strings.HasSuffix(bases[i], sep) {
+			bases[i] += sep
+		}
+	}
+	return abs(p.wd, Ancestor(bases...))
+}
+
+// compileSegments compiles the segments of the pattern, relative to wd, beneath root.
+// It returns nil if the pattern does not lie beneath root or has an extglob containing a '/'.
+func compileSegments(pattern, root, wd string, syn syntax) []segment {
+	full, root := filepath.ToSlash(abs(wd, pattern)), filepath.ToSlash(root)
+	if !strings.HasPrefix(full, root) {
+		return nil
+	}
+	rest := strings.TrimPrefix(full[len(root):], "/")
+	var segs []segment
+	depth, start := 0, 0
+	for i := 0; i <= len(rest); i++ {
+		switch {
+		case i < len(rest) && rest[i] == '\\':
+			i++
+		case i < len(rest) && rest[i] == '(':
+			depth++
+		case i < len(rest) && rest[i] == ')' && depth > 0:
+			depth--
+		case i == len(rest) || rest[i] == '/':
+			if depth > 0 {
+				return nil
+			}
+			seg := rest[start:i]
+			start = i + 1
+			if seg == "**" && i < len(rest) {
+				segs = append(segs, segment{globstar: true})
+				continue
+			}
+			m, err := compileSlash(seg, true, syn)
+			if err != nil {
+				return nil
+			}
+			segs = append(segs, segment{m: m})
+		}
+	}
+	return segs
+}
+
+// add appends s to states, along with every state following the globstars it begins with.
+func (w *walker) add(states []state, s state) []state {
+	for {
+		for _, t := range states {
+			if t == s {
+				return states
+			}
+		}
+		states = append(states, s)
+		segs := w.segs[s.e]
+		if segs == nil || s.i >= len(segs) || !segs[s.i].globstar {
+			return states
+		}
+		s.i++
+	}
+}
+
+// step returns the states reached by matching name against states.
+func (w *walker) step(states []state, name string) []state {
+	var next []state
+	for _, s := range states {
+		segs := w.segs[s.e]
+		switch {
+		case segs == nil:
+			next = w.add(next, s)
+		case s.i >= len(segs):
+		case segs[s.i].globstar:
+			if w.dot || !strings.HasPrefix(name, ".") {
+				next = w.add(next, s)
+			}
+		case segs[s.i].m.MatchString(name):
+			next = w.add(next, state{s.e, s.i + 1})
+		}
+	}
+	return next
+}
+
+// complete reports whether any of states could have matched the entirety of its pattern.
+func (w *walker) complete(states []state) bool {
+	for _, s := range states {
+		if segs := w.segs[s.e]; segs == nil || s.i == len(segs) {
+			return true
+		}
+	}
+	return false
+}
+
+// partial reports whether any of states could match further path elements.
+func (w *walker) partial(states []state) bool {
+	for _, s := range states {
+		if segs := w.segs[s.e]; segs == nil || s.i < len(segs) {
+			return true
+		}
+	}
+	return false
+}
+
+// stopped reports whether the walk has stopped because of an error.
+func (w *walker) stopped() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return !w.permissive && len(w.errs) > 0
+}
+
+// dir matches the entries of dir, which has reached states, and walks its subdirectories.
+// Subdirectories are walked concurrently while fewer than runtime.NumCPU are in progress.
+//
+// Symlinks to directories are followed, as filepath.Glob follows them, unless they lead back into the walk.
+// real is dir with its symlinks resolved, and links holds the real root of the walk
+// along with the resolved targets of every symlink followed to reach dir.
+func (w *walker) dir(dir, real string, links []string, states []state) {
+	defer w.wg.Done()
+	entries, err := os.ReadDir(dir)
+	// A directory removed during the walk simply has no matches.
+	if err != nil && !os.IsNotExist(err) {
+		w.mu.Lock()
+		w.errs = append(w.errs, ErrPath{Path: dir, Err: err})
+		w.mu.Unlock()
+	}
+	for _, e := range entries {
+		if w.stopped() {
+			return
+		}
+		path := filepath.Join(dir, e.Name())
+		isDir := e.IsDir()
+		next := w.step(states, e.Name())
+		if len(next) == 0 {
+			continue
+		}
+		sub, followed := filepath.Join(real, e.Name()), links
+		if e.Type()&os.ModeSymlink != 0 {
+			if target, err := filepath.EvalSymlinks(path); err == nil {
+				fi, err := os.Stat(target)
+				isDir = err == nil && fi.IsDir()
+				sub, followed = target, append(links[:len(links):len(links)], target)
+			}
+		}
+		if w.ignore != nil && w.ignore.ignored(path, isDir) {
+			continue
+		}
+		if w.complete(next) && w.m.MatchString(filepath.ToSlash(path)) {
+			w.mu.Lock()
+			w.matches = append(w.matches, path)
+			w.mu.Unlock()
+		}
+		if !isDir || !w.partial(next) || cycle(sub, real, links) {
+			continue
+		}
+		w.wg.Add(1)
+		select {
+		case w.sem <- struct{}{}:
+			go func() {
+				w.dir(path, sub, followed, next)
+				<-w.sem
+			}()
+		default:
+			w.dir(path, sub, followed, next)
+		}
+	}
+}
+
+// cycle reports whether walking target, a resolved subdirectory of the directory whose resolved path is real,
+// would revisit a directory on the way to it: that is, whether target contains real or one of links.
+func cycle(target, real string, links []string) bool {
+	if within(target, real) {
+		return true
+	}
+	for _, l := range links {
+		if within(target, l) {
+			return true
+		}
+	}
+	return false
+}
+
+// within reports whether name is dir or lies beneath it.
+func within(dir, name string) bool {
+	rel, err := filepath.Rel(dir, name)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
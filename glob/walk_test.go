@@ -0,0 +1,156 @@
+package glob_test
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	. "github.com/SaidinWoT/gulf/glob"
+)
+
+var walkFiles = []string{
+	"a.go",
+	"src/b.go",
+	"src/b_test.go",
+	"src/c/d.go",
+	"src/c/d.txt",
+	"src/.cache/e.go",
+	"vendor/f/g.go",
+	"node_modules/h/i.go",
+}
+
+var walks = map[string][]string{
+	"**/*.go":                {"a.go", "node_modules/h/i.go", "src/b.go", "src/b_test.go", "src/c/d.go", "vendor/f/g.go"},
+	"src/**/*.{go,txt}":      {"src/b.go", "src/b_test.go", "src/c/d.go", "src/c/d.txt"},
+	"src/**/.*/*.go":         {"src/.cache/e.go"},
+	"!(vendor)/**/*_test.go": {"src/b_test.go"},
+	"**/@(c|f)":              {"src/c", "vendor/f"},
+}
+
+// tempDir creates a temporary directory for the test containing the named empty files, returning its path.
+// Slash-separated names ending with '/' are created as directories.
+func tempDir(t *testing.T, names ...string) string {
+	root := t.TempDir()
+	for _, name := range names {
+		if strings.HasSuffix(name, "/") {
+			os.MkdirAll(filepath.Join(root, filepath.FromSlash(name)), 0755)
+			continue
+		}
+		writeFile(root, name, "")
+	}
+	return root
+}
+
+// writeFile writes content to the slash-separated name beneath root, creating its parent directories.
+func writeFile(root, name, content string) {
+	path := filepath.Join(root, filepath.FromSlash(name))
+	os.MkdirAll(filepath.Dir(path), 0755)
+	os.WriteFile(path, []byte(content), 0644)
+}
+
+// chdir changes into dir for the duration of the test.
+func chdir(t *testing.T, dir string) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.Chdir(dir)
+	t.Cleanup(func() {
+		os.Chdir(wd)
+	})
+}
+
+// lock makes dir unreadable for the duration of the test, skipping it if permissions are not enforced.
+func lock(t *testing.T, dir string) string {
+	os.Chmod(dir, 0)
+	t.Cleanup(func() {
+		os.Chmod(dir, 0755)
+	})
+	if _, err := os.ReadDir(dir); err == nil {
+		t.Skip("Permissions are not enforced")
+	}
+	return dir
+}
+
+func TestWalk(t *testing.T) {
+	root := tempDir(t, walkFiles...)
+	for pattern, names := range walks {
+		expected := make([]string, len(names))
+		for i, name := range names {
+			expected[i] = filepath.Join(root, name)
+		}
+		ms, err := Glob(filepath.Join(root, pattern))
+		if err != nil {
+			t.Error(err)
+		}
+		if !reflect.DeepEqual(ms, expected) {
+			t.Errorf(`Glob: "%s" produced %q`, pattern, ms)
+		}
+	}
+}
+
+func TestWalkErrors(t *testing.T) {
+	root := tempDir(t, "a/x.go", "b/y.go", "c/z.go")
+	locked := lock(t, filepath.Join(root, "b"))
+	// Patterns without a globstar or extglob read directories just the same.
+	for _, pattern := range []string{"**/*.go", "*/*.go"} {
+		pattern = filepath.Join(root, pattern)
+
+		_, err := Glob(pattern)
+		if e, ok := err.(ErrPath); !ok || e.Path != locked {
+			t.Errorf("Glob: %s reported %v rather than an ErrPath for %s", pattern, err, locked)
+		}
+
+		ms, err := GlobFunc(Permissive(true))(pattern)
+		if e, ok := err.(ErrPaths); !ok || len(e) != 1 || e[0].Path != locked {
+			t.Errorf("Glob: %s reported %v rather than ErrPaths for %s", pattern, err, locked)
+		}
+		expected := []string{filepath.Join(root, "a/x.go"), filepath.Join(root, "c/z.go")}
+		if !reflect.DeepEqual(ms, expected) {
+			t.Errorf("Glob: %s permissively produced %q", pattern, ms)
+		}
+	}
+}
+
+func TestWalkBraces(t *testing.T) {
+	root := tempDir(t, "here/a.go", "other/w.go", "other/sub/x.go")
+	chdir(t, filepath.Join(root, "here"))
+	// The prefix of the second expansion lies outside of the pattern's Base.
+	ms, err := Glob("{.,../other}/*.go")
+	if err != nil {
+		t.Error(err)
+	}
+	expected := []string{filepath.Join(root, "here/a.go"), filepath.Join(root, "other/w.go")}
+	if !reflect.DeepEqual(ms, expected) {
+		t.Errorf(`Glob: "{.,../other}/*.go" produced %q`, ms)
+	}
+}
+
+func TestWalkSymlinks(t *testing.T) {
+	root := tempDir(t, "real/z.go", "real/sub/y.go", "here/")
+	if err := os.Symlink("real", filepath.Join(root, "link")); err != nil {
+		t.Skip("Symlinks are not supported:", err)
+	}
+	// A symlink back to the root must not be walked forever.
+	os.Symlink("..", filepath.Join(root, "real", "loop"))
+	chdir(t, filepath.Join(root, "here"))
+	for pattern, names := range map[string][]string{
+		"../*/z.go":  {"link/z.go", "real/z.go"},
+		"../**/*.go": {"link/sub/y.go", "link/z.go", "real/sub/y.go", "real/z.go"},
+		"../*/loop":  {"link/loop", "real/loop"},
+	} {
+		var expected []string
+		for _, name := range names {
+			expected = append(expected, filepath.Join(root, name))
+		}
+		ms, err := Glob(pattern)
+		if err != nil {
+			t.Error(err)
+		}
+		if !reflect.DeepEqual(ms, expected) {
+			t.Errorf(`Glob: "%s" produced %q`, pattern, ms)
+		}
+	}
+}
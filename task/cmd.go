@@ -0,0 +1,89 @@
+package task
+
+import (
+	"context"
+	"io"
+	osexec "os/exec"
+	"time"
+)
+
+// Command describes an external program to be run as a task.
+type Command struct {
+	Name  string    // The program to run.
+	Args  []string  // Arguments to the program, not including its name.
+	Dir   string    // The working directory; empty means the current directory.
+	Env   []string  // The environment; nil means the current process's environment.
+	Stdin io.Reader // The program's standard input; nil means the null device.
+	// Output receives the program's standard output and error; nil means the Writer in the Context.
+	Output io.Writer
+
+	// Grace is the time the program is given to exit after being interrupted on cancellation,
+	// after which it is killed. Zero kills it immediately.
+	Grace time.Duration
+}
+
+// Cmd returns a task function which runs the named program with the given arguments.
+// It is shorthand for the Run method of a Command.
+func Cmd(name string, args ...string) func(context.Context) error {
+	c := &Command{
+		Name: name,
+		Args: args,
+	}
+	return c.Run
+}
+
+// Run runs the command, writing its standard output and error to its Output or the Writer in ctx.
+//
+// If ctx is done before the command exits, the command's entire process group is killed,
+// after first being interrupted if the command has a Grace period.
+// Any failure, including a non-zero exit status, is returned as an ErrCmd.
+func (c *Command) Run(ctx context.Context) error {
+	cmd := osexec.Command(c.Name, c.Args...)
+	cmd.Dir, cmd.Env, cmd.Stdin = c.Dir, c.Env, c.Stdin
+	w := c.Output
+	if w == nil {
+		w = Writer(ctx)
+	}
+	cmd.Stdout, cmd.Stderr = w, w
+	setpgid(cmd)
+	if err := cmd.Start(); err != nil {
+		return c.err(-1, err)
+	}
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-done:
+			return
+		}
+		if c.Grace > 0 {
+			interruptpg(cmd)
+			select {
+			case <-time.After(c.Grace):
+			case <-done:
+				return
+			}
+		}
+		killpg(cmd)
+	}()
+
+	err := cmd.Wait()
+	if ctx.Err() != nil {
+		return c.err(cmd.ProcessState.ExitCode(), ctx.Err())
+	}
+	if err != nil {
+		return c.err(cmd.ProcessState.ExitCode(), err)
+	}
+	return nil
+}
+
+func (c *Command) err(code int, err error) ErrCmd {
+	return ErrCmd{
+		Name: c.Name,
+		Args: c.Args,
+		Code: code,
+		Err:  err,
+	}
+}
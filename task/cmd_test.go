@@ -0,0 +1,65 @@
+package task_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	. "github.com/SaidinWoT/gulf/task"
+)
+
+func TestCmd(t *testing.T) {
+	s := New()
+	out := new(bytes.Buffer)
+	s.SetOption(Output(out))
+	s.TaskContext("sh", Cmd("sh", "-c", "echo hello; exit 3"))
+	err := s.Exec("sh")
+	e, ok := err.(*ErrExec)
+	if !ok {
+		t.Fatalf("Exec returned %v rather than an ErrExec.", err)
+	}
+	if c, ok := e.Task.(ErrCmd); !ok || c.Code != 3 {
+		t.Errorf("Cmd reported %v rather than exit status 3.", e.Task)
+	}
+	if got := out.String(); got != "[sh] hello\n" {
+		t.Errorf("Cmd output was %q.", got)
+	}
+}
+
+func TestCmdCancel(t *testing.T) {
+	s := New()
+	s.TaskContext("sleep", Cmd("sh", "-c", "sleep 10; sleep 10"))
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	err := s.ExecContext(ctx, "sleep")
+	if time.Since(start) > 5*time.Second {
+		t.Error("Cmd was not killed when its Context was canceled.")
+	}
+	if err == nil {
+		t.Error("Canceled Cmd did not report an error.")
+	}
+}
+
+func TestCmdGrace(t *testing.T) {
+	c := &Command{
+		Name:  "sh",
+		Args:  []string{"-c", "trap 'echo bye; exit 0' TERM; sleep 10 & wait"},
+		Grace: 5 * time.Second,
+	}
+	out := new(bytes.Buffer)
+	s := New()
+	s.SetOption(Output(out))
+	s.TaskContext("graceful", c.Run)
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	s.ExecContext(ctx, "graceful")
+	if time.Since(start) > c.Grace {
+		t.Error("Cmd was not interrupted when its Context was canceled.")
+	}
+	if got := out.String(); got != "[graceful] bye\n" {
+		t.Errorf("Cmd did not exit gracefully, writing %q.", got)
+	}
+}
@@ -0,0 +1,23 @@
+//go:build !windows
+// +build !windows
+
+package task
+
+import (
+	osexec "os/exec"
+	"syscall"
+)
+
+// setpgid places the command in its own process group,
+// so that killpg reaches any children it spawns.
+func setpgid(cmd *osexec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+}
+
+func killpg(cmd *osexec.Cmd) {
+	syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+}
+
+func interruptpg(cmd *osexec.Cmd) {
+	syscall.Kill(-cmd.Process.Pid, syscall.SIGTERM)
+}
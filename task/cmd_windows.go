@@ -0,0 +1,13 @@
+package task
+
+import osexec "os/exec"
+
+// setpgid is a no-op; Windows processes are killed individually.
+func setpgid(cmd *osexec.Cmd) {}
+
+func killpg(cmd *osexec.Cmd) {
+	cmd.Process.Kill()
+}
+
+// interruptpg is a no-op; Windows processes cannot be interrupted.
+func interruptpg(cmd *osexec.Cmd) {}
@@ -0,0 +1,82 @@
+package task
+
+import (
+	"context"
+	"strconv"
+)
+
+// Series returns a task function which runs each of the provided tasks in order,
+// stopping at the first required task to fail or once its Context is done.
+//
+// Each task may be either the name of a task registered in s (flags included),
+// a func() error, or a func(context.Context) error, which is called with the Series' Context.
+// Named tasks are run with ExecContext, so each resolves its own dependencies and is cancelled along with the Series.
+// Functions are identified in the resulting ErrExec by their position, as "#0", "#1", and so on.
+func (s *Set) Series(tasks ...interface{}) func(context.Context) error {
+	return func(ctx context.Context) error {
+		errs := newErrExec()
+		for i, t := range tasks {
+			name, f, fn := s.compose(i, t)
+			if err := ctx.Err(); err != nil {
+				errs.Add(name, err, false)
+				return errs
+			}
+			if err := fn(ctx); err != nil {
+				errs.Add(name, err, f.optional)
+				if !f.optional {
+					return errs
+				}
+			}
+		}
+		return nil
+	}
+}
+
+// Parallel returns a task function which runs all of the provided tasks concurrently.
+// The tasks accepted are the same as for Series.
+//
+// Any failures are collected into an ErrExec once every task has returned.
+func (s *Set) Parallel(tasks ...interface{}) func(context.Context) error {
+	return func(ctx context.Context) error {
+		errs := newErrExec()
+		done := make(chan struct{})
+		for i, t := range tasks {
+			go func(i int, t interface{}) {
+				name, f, fn := s.compose(i, t)
+				if err := fn(ctx); err != nil {
+					errs.Add(name, err, f.optional)
+				}
+				done <- struct{}{}
+			}(i, t)
+		}
+		for range tasks {
+			<-done
+		}
+		if errs.Failed() {
+			return errs
+		}
+		return nil
+	}
+}
+
+// compose resolves one of the tasks given to Series or Parallel into a name,
+// the flags that apply to it, and a function running it.
+func (s *Set) compose(i int, t interface{}) (string, flags, func(context.Context) error) {
+	switch t := t.(type) {
+	case string:
+		f, name := parseFlags(t)
+		return name, f, func(ctx context.Context) error {
+			return s.ExecContext(ctx, name)
+		}
+	case func() error:
+		return "#" + strconv.Itoa(i), flags{}, func(context.Context) error {
+			return t()
+		}
+	case func(context.Context) error:
+		return "#" + strconv.Itoa(i), flags{}, t
+	}
+	name := "#" + strconv.Itoa(i)
+	return name, flags{}, func(context.Context) error {
+		return ErrTaskType{name: name}
+	}
+}
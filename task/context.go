@@ -0,0 +1,35 @@
+package task
+
+import (
+	"context"
+	"io"
+	"os"
+)
+
+type contextKey int
+
+const (
+	nameKey contextKey = iota
+	writerKey
+)
+
+func withTask(ctx context.Context, name string, w io.Writer) context.Context {
+	ctx = context.WithValue(ctx, nameKey, name)
+	return context.WithValue(ctx, writerKey, w)
+}
+
+// Name returns the name of the task running with ctx.
+// If ctx was not provided to a task, the empty string is returned.
+func Name(ctx context.Context) string {
+	name, _ := ctx.Value(nameKey).(string)
+	return name
+}
+
+// Writer returns the Writer for the task running with ctx.
+// If ctx was not provided to a task, os.Stdout is returned.
+func Writer(ctx context.Context) io.Writer {
+	if w, ok := ctx.Value(writerKey).(io.Writer); ok {
+		return w
+	}
+	return os.Stdout
+}
@@ -2,6 +2,7 @@ package task
 
 import (
 	"errors"
+	"strconv"
 	"strings"
 	"sync"
 )
@@ -34,6 +35,47 @@ func (e ErrTaskNotExist) Error() string {
 	return "Task " + e.name + " does not exist."
 }
 
+// ErrTaskType is returned when a task given to Series or Parallel is neither a name nor a function.
+type ErrTaskType struct {
+	name string
+}
+
+func (e ErrTaskType) Error() string {
+	return "Task " + e.name + " is neither a task name nor a task function."
+}
+
+// ErrSameResource indicates that a resource has already been declared with the provided name.
+type ErrSameResource struct {
+	name string
+}
+
+func (e ErrSameResource) Error() string {
+	return "You declared multiple resources named: " + e.name
+}
+
+// ErrCapacity is returned if a resource is declared with a capacity less than 1.
+var ErrCapacity = errors.New("You declared a resource with a capacity less than 1")
+
+// ErrCmd indicates that a Command failed to start or exited unsuccessfully.
+type ErrCmd struct {
+	Name string   // The program run.
+	Args []string // The arguments provided to the program.
+	Code int      // The exit status, or -1 if the program did not exit normally.
+	Err  error    // The underlying error.
+}
+
+func (e ErrCmd) Error() string {
+	cmd := strings.Join(append([]string{e.Name}, e.Args...), " ")
+	if e.Code > 0 {
+		return cmd + ": exit status " + strconv.Itoa(e.Code)
+	}
+	return cmd + ": " + e.Err.Error()
+}
+
+func (e ErrCmd) Unwrap() error {
+	return e.Err
+}
+
 // ErrExec indicates any failures encountered while executing a task.
 type ErrExec struct {
 	sync.Mutex
@@ -0,0 +1,68 @@
+package task
+
+// EventType identifies the point in a task's lifecycle that an Event describes.
+type EventType int
+
+const (
+	// TaskStart is sent when a task's dependencies have succeeded and it begins running.
+	TaskStart EventType = iota
+	// TaskSkipped is sent when a task is not run because a required dependency failed
+	// or its Context was done.
+	TaskSkipped
+	// TaskSucceeded is sent when a task returns without error.
+	TaskSucceeded
+	// TaskFailed is sent when a task returns an error.
+	TaskFailed
+	// DependencyOptionalFailure is sent when an optional dependency of a task fails.
+	DependencyOptionalFailure
+)
+
+var eventNames = [...]string{
+	TaskStart:                 "TaskStart",
+	TaskSkipped:               "TaskSkipped",
+	TaskSucceeded:             "TaskSucceeded",
+	TaskFailed:                "TaskFailed",
+	DependencyOptionalFailure: "DependencyOptionalFailure",
+}
+
+func (t EventType) String() string {
+	if t < 0 || int(t) >= len(eventNames) {
+		return "EventType(?)"
+	}
+	return eventNames[t]
+}
+
+// An Event describes a change in the state of a task during Exec.
+type Event struct {
+	Type EventType
+	Task string // The name of the task the event concerns.
+	Dep  string // The failed dependency, for DependencyOptionalFailure only.
+	Err  error  // The *ErrExec for TaskSkipped and TaskFailed, or the dependency's error.
+}
+
+// An Observer is notified of every Event that occurs while a Set executes tasks.
+//
+// Notify is called synchronously from the goroutine running the task,
+// and may be called concurrently for tasks running in parallel.
+type Observer interface {
+	Notify(Event)
+}
+
+// ObserverFunc adapts an ordinary function to the Observer interface.
+type ObserverFunc func(Event)
+
+// Notify calls f(e).
+func (f ObserverFunc) Notify(e Event) {
+	f(e)
+}
+
+// Observe registers observers to be notified of task lifecycle events.
+func (s *Set) Observe(obs ...Observer) {
+	s.obs = append(s.obs, obs...)
+}
+
+func (s *Set) notify(e Event) {
+	for _, o := range s.obs {
+		o.Notify(e)
+	}
+}
@@ -1,17 +1,27 @@
 package task
 
-import "sync"
+import (
+	"context"
+	"sync"
+)
 
 // Exec runs the task with the provided name after resolving all of its dependencies.
 // As long as the task exists, any error returned will be an ErrExec,
 // which may be introspected for the errors returned by the dependencies.
 func (s *Set) Exec(name string) error {
+	return s.ExecContext(context.Background(), name)
+}
+
+// ExecContext is like Exec, but runs every task with a Context derived from ctx.
+// Once ctx is done, no further tasks are started and each is reported as failing with ctx's error.
+func (s *Set) ExecContext(ctx context.Context, name string) error {
 	if s.err != nil {
 		return s.err
 	}
 	e := &exec{
-		fs: make(map[string]func() error),
-		ts: s.ts,
+		ctx: ctx,
+		fs:  make(map[string]func() error),
+		s:   s,
 	}
 	t, ok := s.ts[name]
 	if !ok {
@@ -22,8 +32,9 @@ func (s *Set) Exec(name string) error {
 
 type exec struct {
 	sync.RWMutex
-	fs map[string]func() error
-	ts map[string]task
+	ctx context.Context
+	fs  map[string]func() error
+	s   *Set
 }
 
 func (e *exec) run(t task) error {
@@ -34,7 +45,7 @@ func (e *exec) run(t task) error {
 		go func(d string) {
 			var err error
 			f, d := parseFlags(d)
-			dt, ok := e.ts[d]
+			dt, ok := e.s.ts[d]
 			if !ok {
 				err = ErrTaskNotExist{name: d}
 			} else if dt.f.multi || f.multi {
@@ -43,19 +54,35 @@ func (e *exec) run(t task) error {
 				err = e.runOnce(dt)
 			}
 			if err != nil {
-				errs.Add(d, err, dt.f.optional || f.optional)
+				optional := dt.f.optional || f.optional
+				errs.Add(d, err, optional)
+				if optional {
+					e.s.notify(Event{Type: DependencyOptionalFailure, Task: t.name, Dep: d, Err: err})
+				}
 			}
 			wg.Done()
 		}(dep)
 	}
 	wg.Wait()
 	if errs.Failed() {
+		e.s.notify(Event{Type: TaskSkipped, Task: t.name, Err: errs})
+		return errs
+	}
+	release, err := e.s.acquire(e.ctx, t.res)
+	if err != nil {
+		errs.Task = err
+		e.s.notify(Event{Type: TaskSkipped, Task: t.name, Err: errs})
 		return errs
 	}
-	errs.Task = t.fn()
+	e.s.notify(Event{Type: TaskStart, Task: t.name})
+	errs.Task = t.fn(withTask(e.ctx, t.name, e.s.Writer(t.name)))
+	release()
+	e.s.out.flush(t.name)
 	if errs.Task != nil {
+		e.s.notify(Event{Type: TaskFailed, Task: t.name, Err: errs})
 		return errs
 	}
+	e.s.notify(Event{Type: TaskSucceeded, Task: t.name})
 	return nil
 }
 
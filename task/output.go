@@ -0,0 +1,208 @@
+package task
+
+import (
+	"bytes"
+	"hash/fnv"
+	"io"
+	"log"
+	"os"
+	"strconv"
+	"sync"
+)
+
+// The Option type is a function that modifies a Set.
+type Option func(s *Set) error
+
+// SetOption modifies s with the provided Options.
+func (s *Set) SetOption(opts ...Option) error {
+	for _, opt := range opts {
+		if err := opt(s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Output sets the io.Writer that all task output is written to.
+//
+// The default is os.Stdout
+func Output(w io.Writer) Option {
+	return func(s *Set) error {
+		s.out.Lock()
+		s.out.w = w
+		s.out.Unlock()
+		return nil
+	}
+}
+
+// Color enables or disables colorizing the task name prefixing each line of output.
+//
+// The default is false
+func Color(on bool) Option {
+	return func(s *Set) error {
+		s.out.Lock()
+		s.out.color = on
+		s.out.Unlock()
+		return nil
+	}
+}
+
+// Buffer enables or disables buffering each task's output until it completes.
+// Buffered output is written all at once, so it is never interleaved with that of other tasks.
+//
+// The default is false
+func Buffer(on bool) Option {
+	return func(s *Set) error {
+		s.out.Lock()
+		s.out.buffer = on
+		s.out.Unlock()
+		return nil
+	}
+}
+
+// Writer returns an io.Writer for the named task's output.
+// Each line written is prefixed with the task name before being written to the Set's Output,
+// which makes it suitable for the Stdout and Stderr of subprocesses.
+//
+// Partial lines, and all output when buffering, are written once the task completes.
+func (s *Set) Writer(name string) io.Writer {
+	return s.out.writer(name)
+}
+
+// Stream returns an io.WriteCloser for output of the named task which outlives its execution,
+// such as that of a long-running process.
+// Unlike Writer, each line is written as soon as it is complete, even when buffering,
+// and a trailing partial line is written by Close.
+func (s *Set) Stream(name string) io.WriteCloser {
+	return &taskWriter{
+		o:      s.out,
+		name:   name,
+		stream: true,
+	}
+}
+
+// Logger returns a *log.Logger writing to the named task's Writer.
+func (s *Set) Logger(name string) *log.Logger {
+	return log.New(s.Writer(name), "", log.LstdFlags)
+}
+
+type output struct {
+	sync.Mutex
+	w      io.Writer
+	color  bool
+	buffer bool
+	ws     map[string]*taskWriter
+}
+
+func newOutput() *output {
+	return &output{
+		w:  os.Stdout,
+		ws: make(map[string]*taskWriter),
+	}
+}
+
+// colors are the ANSI foreground colors used for task name prefixes.
+var colors = []int{31, 32, 33, 34, 35, 36}
+
+func (o *output) writer(name string) *taskWriter {
+	o.Lock()
+	defer o.Unlock()
+	if tw, ok := o.ws[name]; ok {
+		return tw
+	}
+	tw := &taskWriter{
+		o:    o,
+		name: name,
+	}
+	o.ws[name] = tw
+	return tw
+}
+
+// prefix returns the prefix for each line of the named task's output.
+// It is built for every line, so that Color applies to existing Writers.
+func (o *output) prefix(name string) string {
+	o.Lock()
+	defer o.Unlock()
+	if !o.color {
+		return "[" + name + "] "
+	}
+	h := fnv.New32a()
+	h.Write([]byte(name))
+	c := colors[h.Sum32()%uint32(len(colors))]
+	return "\x1b[" + strconv.Itoa(c) + "m[" + name + "]\x1b[0m "
+}
+
+// flush writes any output held for the named task.
+func (o *output) flush(name string) {
+	o.Lock()
+	tw, ok := o.ws[name]
+	o.Unlock()
+	if ok {
+		tw.flush()
+	}
+}
+
+func (o *output) buffered() bool {
+	o.Lock()
+	defer o.Unlock()
+	return o.buffer
+}
+
+func (o *output) write(p []byte) {
+	o.Lock()
+	o.w.Write(p)
+	o.Unlock()
+}
+
+type taskWriter struct {
+	sync.Mutex
+	o       *output
+	name    string
+	stream  bool // Write lines immediately, regardless of buffering.
+	partial []byte
+	buf     bytes.Buffer
+}
+
+func (tw *taskWriter) Write(p []byte) (int, error) {
+	tw.Lock()
+	defer tw.Unlock()
+	tw.partial = append(tw.partial, p...)
+	for {
+		i := bytes.IndexByte(tw.partial, '\n')
+		if i < 0 {
+			break
+		}
+		tw.line(tw.partial[:i+1])
+		tw.partial = tw.partial[i+1:]
+	}
+	return len(p), nil
+}
+
+// line writes a single newline-terminated line, or holds it if buffering.
+func (tw *taskWriter) line(l []byte) {
+	tw.buf.WriteString(tw.o.prefix(tw.name))
+	tw.buf.Write(l)
+	if tw.stream || !tw.o.buffered() {
+		tw.o.write(tw.buf.Bytes())
+		tw.buf.Reset()
+	}
+}
+
+func (tw *taskWriter) flush() {
+	tw.Lock()
+	defer tw.Unlock()
+	if len(tw.partial) > 0 {
+		tw.line(append(tw.partial, '\n'))
+		tw.partial = nil
+	}
+	if tw.buf.Len() > 0 {
+		tw.o.write(tw.buf.Bytes())
+		tw.buf.Reset()
+	}
+}
+
+// Close writes any partial line held by the writer.
+func (tw *taskWriter) Close() error {
+	tw.flush()
+	return nil
+}
@@ -0,0 +1,107 @@
+package task
+
+import (
+	"context"
+	"sort"
+)
+
+// Resource declares a named resource which at most capacity tasks may hold at once.
+// Tasks sharing a resource will not run concurrently beyond its capacity,
+// regardless of their positions in the dependency graph.
+//
+// Resources referenced by Use but never declared have a capacity of 1.
+// Resource and Use may be called in either order.
+func (s *Set) Resource(name string, capacity int) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.rmu.Lock()
+	defer s.rmu.Unlock()
+	if capacity < 1 {
+		s.err = ErrCapacity
+	} else if _, exists := s.rs[name]; exists {
+		s.err = ErrSameResource{name: name}
+	}
+	if s.err != nil {
+		return s.err
+	}
+	s.rs[name] = make(chan struct{}, capacity)
+	return nil
+}
+
+// Use marks the named task as holding the provided resources while it runs.
+// Resources are acquired after the task's dependencies have completed
+// and released as soon as the task itself returns.
+func (s *Set) Use(name string, resources ...string) error {
+	if s.err != nil {
+		return s.err
+	}
+	_, name = parseFlags(name)
+	t, ok := s.ts[name]
+	if !ok {
+		s.err = ErrTaskNotExist{name}
+		return s.err
+	}
+	t.res = append(t.res, resources...)
+	// Acquiring resources in a consistent order prevents tasks from deadlocking one another.
+	sort.Strings(t.res)
+	t.res = unique(t.res)
+	s.ts[name] = t
+	return nil
+}
+
+// semaphore returns the channel limiting the holders of the named resource.
+// A resource that was never declared is given a capacity of 1 the first time it is needed.
+func (s *Set) semaphore(name string) chan struct{} {
+	s.rmu.Lock()
+	defer s.rmu.Unlock()
+	sem, exists := s.rs[name]
+	if !exists {
+		sem = make(chan struct{}, 1)
+		s.rs[name] = sem
+	}
+	return sem
+}
+
+// acquire blocks until every resource in rs has been acquired or ctx is done.
+// The returned function releases them.
+func (s *Set) acquire(ctx context.Context, rs []string) (func(), error) {
+	sems := make([]chan struct{}, len(rs))
+	for i, r := range rs {
+		sems[i] = s.semaphore(r)
+	}
+	release := func(sems []chan struct{}) {
+		for _, sem := range sems {
+			<-sem
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	for i, sem := range sems {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			release(sems[:i])
+			return nil, ctx.Err()
+		}
+	}
+	return func() {
+		release(sems)
+	}, nil
+}
+
+// unique removes adjacent duplicates from a sorted slice.
+func unique(ss []string) []string {
+	if len(ss) == 0 {
+		return ss
+	}
+	j := 1
+	for _, s := range ss[1:] {
+		if s != ss[j-1] {
+			ss[j] = s
+			j++
+		}
+	}
+	return ss[:j]
+}
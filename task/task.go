@@ -1,11 +1,19 @@
 // Package task provides a task management system capable of resolving single-use, multi-use, and optional dependencies.
 package task
 
-import "strings"
+import (
+	"context"
+	"strings"
+	"sync"
+)
 
 // The Set type provides a structure to register a set of tasks and execute them.
 type Set struct {
 	ts  map[string]task
+	rs  map[string]chan struct{}
+	rmu sync.Mutex // Guards rs, as undeclared resources are added during execution.
+	obs []Observer
+	out *output
 	err error
 }
 
@@ -13,13 +21,16 @@ type Set struct {
 func New() *Set {
 	return &Set{
 		ts:  make(map[string]task),
+		rs:  make(map[string]chan struct{}),
+		out: newOutput(),
 	}
 }
 
 type task struct {
 	name string
 	deps []string
-	fn   func() error
+	res  []string
+	fn   func(context.Context) error
 	f    flags
 }
 
@@ -40,11 +51,8 @@ type flags struct {
 
 func parseFlags(name string) (flags, string) {
 	var f flags
-	l := len(name)
-	for {
-		name = name[:l]
-		l = len(name) - 1
-		switch c := name[l]; c {
+	for l := len(name); l > 0; l-- {
+		switch c := name[l-1]; c {
 		case '*':
 			f.multi = true
 			f.optional = true
@@ -53,9 +61,10 @@ func parseFlags(name string) (flags, string) {
 		case '?':
 			f.optional = true
 		default:
-			return f, name
+			return f, name[:l]
 		}
 	}
+	return f, ""
 }
 
 // Task registers a task, correlating a function with a name and an optional set of dependencies.
@@ -65,6 +74,15 @@ func parseFlags(name string) (flags, string) {
 // Creating a dependency cycle registers an error in the Set, which will prevent further use of the Set.
 // Any such error will also be returned.
 func (s *Set) Task(name string, fn func() error, deps ...string) error {
+	return s.TaskContext(name, func(context.Context) error {
+		return fn()
+	}, deps...)
+}
+
+// TaskContext is like Task, but registers a function which accepts a Context.
+// The Context is canceled along with the one provided to ExecContext,
+// and carries the task's Name and Writer.
+func (s *Set) TaskContext(name string, fn func(context.Context) error, deps ...string) error {
 	if s.err != nil {
 		return s.err
 	}
@@ -1,7 +1,16 @@
 package task_test
 
 import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	. "github.com/SaidinWoT/gulf/task"
 )
@@ -32,3 +41,235 @@ func TestCycle(t *testing.T) {
 		}
 	}
 }
+
+func TestResource(t *testing.T) {
+	s := New()
+	s.Resource("db", 1)
+	var running, max int32
+	hold := func() error {
+		if n := atomic.AddInt32(&running, 1); n > atomic.LoadInt32(&max) {
+			atomic.StoreInt32(&max, n)
+		}
+		time.Sleep(10 * time.Millisecond)
+		atomic.AddInt32(&running, -1)
+		return nil
+	}
+	s.Task("migrate", hold)
+	s.Task("seed", hold)
+	s.Task("all", returnNil, "migrate", "seed")
+	s.Use("migrate", "db")
+	s.Use("seed", "db")
+	if err := s.Exec("all"); err != nil {
+		t.Fatal(err)
+	}
+	if max != 1 {
+		t.Errorf("Tasks sharing a resource of capacity 1 ran %d at once.", max)
+	}
+}
+
+func TestResourceErrors(t *testing.T) {
+	s := New()
+	if err := s.Resource("db", 0); err != ErrCapacity {
+		t.Errorf("Resource with capacity 0 reported %v.", err)
+	}
+	s = New()
+	s.Resource("db", 2)
+	if _, ok := s.Resource("db", 1).(ErrSameResource); !ok {
+		t.Error("Redeclaring a resource was not reported.")
+	}
+	s = New()
+	if _, ok := s.Use("missing", "db").(ErrTaskNotExist); !ok {
+		t.Error("Using a resource from a missing task was not reported.")
+	}
+	if _, ok := s.Task("later", returnNil).(ErrTaskNotExist); !ok {
+		t.Error("Using a resource from a missing task was not recorded in the Set.")
+	}
+}
+
+func TestResourceAfterUse(t *testing.T) {
+	s := New()
+	var running, max int32
+	hold := func() error {
+		if n := atomic.AddInt32(&running, 1); n > atomic.LoadInt32(&max) {
+			atomic.StoreInt32(&max, n)
+		}
+		time.Sleep(10 * time.Millisecond)
+		atomic.AddInt32(&running, -1)
+		return nil
+	}
+	s.Task("a", hold)
+	s.Task("b", hold)
+	s.Task("all", returnNil, "a", "b")
+	s.Use("a", "pool")
+	s.Use("b", "pool")
+	if err := s.Resource("pool", 2); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Exec("all"); err != nil {
+		t.Fatal(err)
+	}
+	if max != 2 {
+		t.Errorf("Tasks sharing a resource of capacity 2 ran %d at once.", max)
+	}
+}
+
+func TestSeries(t *testing.T) {
+	s := New()
+	var order []string
+	record := func(name string, err error) func() error {
+		return func() error {
+			order = append(order, name)
+			return err
+		}
+	}
+	fail := errors.New("fail")
+	s.Task("a", record("a", nil))
+	s.Task("b", record("b", fail))
+	err := s.Series("a", "b?", record("c", nil), "b", record("d", nil))(context.Background())
+	if got := strings.Join(order, ""); got != "abcb" {
+		t.Errorf("Series ran %q, expected %q.", got, "abcb")
+	}
+	e, ok := err.(*ErrExec)
+	if !ok {
+		t.Fatalf("Series returned %v rather than an ErrExec.", err)
+	}
+	if _, ok := e.Req["b"]; !ok {
+		t.Error("Series did not report the failed required task.")
+	}
+	if _, ok := e.Opt["b"]; !ok {
+		t.Error("Series did not report the failed optional task.")
+	}
+}
+
+func TestSeriesCancel(t *testing.T) {
+	s := New()
+	started := make(chan struct{})
+	var ran int32
+	s.TaskContext("block", func(ctx context.Context) error {
+		close(started)
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	s.TaskContext("series", s.Series("block", func() error {
+		atomic.AddInt32(&ran, 1)
+		return nil
+	}))
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- s.ExecContext(ctx, "series")
+	}()
+	<-started
+	cancel()
+	select {
+	case err := <-done:
+		if err == nil {
+			t.Error("A cancelled Series reported success.")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Cancelling a Series did not stop the task within it.")
+	}
+	if ran != 0 {
+		t.Error("A cancelled Series ran the task following the cancelled one.")
+	}
+}
+
+func TestParallel(t *testing.T) {
+	s := New()
+	var n int32
+	count := func() error {
+		atomic.AddInt32(&n, 1)
+		return nil
+	}
+	s.Task("count", count)
+	err := s.Parallel("count", count, "missing", 42)(context.Background())
+	if n != 2 {
+		t.Errorf("Parallel ran %d tasks, expected 2.", n)
+	}
+	e, ok := err.(*ErrExec)
+	if !ok {
+		t.Fatalf("Parallel returned %v rather than an ErrExec.", err)
+	}
+	if _, ok := e.Req["missing"].(ErrTaskNotExist); !ok {
+		t.Error("Parallel did not report the missing task.")
+	}
+	if _, ok := e.Req["#3"].(ErrTaskType); !ok {
+		t.Error("Parallel did not report the invalid task.")
+	}
+}
+
+func TestObserve(t *testing.T) {
+	s := New()
+	var mu sync.Mutex
+	events := make(map[string][]EventType)
+	s.Observe(ObserverFunc(func(e Event) {
+		mu.Lock()
+		events[e.Task] = append(events[e.Task], e.Type)
+		mu.Unlock()
+	}))
+	fail := func() error { return errors.New("fail") }
+	s.Task("ok", returnNil)
+	s.Task("opt?", fail)
+	s.Task("req", fail)
+	s.Task("skipped", returnNil, "req")
+	s.Task("all", returnNil, "ok", "opt", "skipped?")
+	s.Exec("all")
+
+	expected := map[string][]EventType{
+		"ok":      {TaskStart, TaskSucceeded},
+		"opt":     {TaskStart, TaskFailed},
+		"req":     {TaskStart, TaskFailed},
+		"skipped": {TaskSkipped},
+		"all":     {DependencyOptionalFailure, DependencyOptionalFailure, TaskStart, TaskSucceeded},
+	}
+	for name, types := range expected {
+		if got := events[name]; !reflect.DeepEqual(got, types) {
+			t.Errorf("Events for %s were %v, expected %v.", name, got, types)
+		}
+	}
+}
+
+func TestBuffer(t *testing.T) {
+	s := New()
+	out := new(bytes.Buffer)
+	s.SetOption(Output(out), Buffer(true))
+	write := func(name string) func() error {
+		return func() error {
+			w := s.Writer(name)
+			io.WriteString(w, "one\ntw")
+			time.Sleep(10 * time.Millisecond)
+			io.WriteString(w, "o\nthree")
+			return nil
+		}
+	}
+	s.Task("a", write("a"))
+	s.Task("b", write("b"))
+	s.Task("all", returnNil, "a", "b")
+	s.Exec("all")
+
+	got := out.String()
+	for _, name := range []string{"a", "b"} {
+		block := "[" + name + "] one\n[" + name + "] two\n[" + name + "] three\n"
+		if !strings.Contains(got, block) {
+			t.Errorf("Output of %s was not written contiguously:\n%s", name, got)
+		}
+	}
+}
+
+func TestColorAfterWriter(t *testing.T) {
+	s := New()
+	out := new(bytes.Buffer)
+	s.SetOption(Output(out))
+	w := s.Writer("a")
+	io.WriteString(w, "plain\n")
+	s.SetOption(Color(true))
+	io.WriteString(w, "colored\n")
+
+	got := out.String()
+	if !strings.HasPrefix(got, "[a] plain\n") {
+		t.Errorf("Output before enabling Color was colorized:\n%q", got)
+	}
+	if !strings.Contains(got, "\x1b[") || !strings.HasSuffix(got, "\x1b[0m colored\n") {
+		t.Errorf("Color did not apply to an existing Writer:\n%q", got)
+	}
+}
@@ -0,0 +1,92 @@
+package watch
+
+import (
+	"sync"
+
+	"gopkg.in/fsnotify.v1"
+)
+
+// A Backend reports operations on the files within a set of directories.
+// Directories are watched individually; the Set adds and removes them to watch recursively.
+//
+// A Set reads from Events and Errors until it calls Close,
+// or until Events is closed.
+type Backend interface {
+	Add(dir string) error
+	Remove(dir string) error
+	Events() <-chan Change
+	Errors() <-chan error
+	Close() error
+}
+
+// notify is a Backend implemented with fsnotify.
+type notify struct {
+	w      *fsnotify.Watcher
+	events chan Change
+	done   chan struct{}
+	once   sync.Once
+}
+
+// NewNotify returns a Backend which receives filesystem events from the operating system via fsnotify.
+func NewNotify() (Backend, error) {
+	w, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	n := &notify{
+		w:      w,
+		events: make(chan Change),
+		done:   make(chan struct{}),
+	}
+	go func() {
+		defer close(n.events)
+		for event := range w.Events {
+			select {
+			case n.events <- Change{Name: event.Name, Op: notifyOp(event.Op)}:
+			case <-n.done:
+				return
+			}
+		}
+	}()
+	return n, nil
+}
+
+func (n *notify) Add(dir string) error {
+	return n.w.Add(dir)
+}
+
+func (n *notify) Remove(dir string) error {
+	return n.w.Remove(dir)
+}
+
+func (n *notify) Events() <-chan Change {
+	return n.events
+}
+
+func (n *notify) Errors() <-chan error {
+	return n.w.Errors
+}
+
+func (n *notify) Close() error {
+	n.once.Do(func() {
+		close(n.done)
+	})
+	return n.w.Close()
+}
+
+// notifyOp converts an fsnotify.Op to an Op.
+func notifyOp(op fsnotify.Op) Op {
+	var o Op
+	for fo, wo := range map[fsnotify.Op]Op{
+		fsnotify.Create: Create,
+		fsnotify.Write:  Write,
+		fsnotify.Remove: Remove,
+		fsnotify.Rename: Rename,
+		fsnotify.Chmod:  Chmod,
+	} {
+		if op&fo != 0 {
+			o |= wo
+		}
+	}
+	return o
+}
@@ -0,0 +1,72 @@
+package watch
+
+import (
+	"context"
+	"strings"
+)
+
+// Op describes a set of filesystem operations.
+type Op uint32
+
+// The operations which may be reported in a Change.
+const (
+	Create Op = 1 << iota
+	Write
+	Remove
+	Rename
+	Chmod
+
+	All = Create | Write | Remove | Rename | Chmod
+)
+
+var opNames = []string{"CREATE", "WRITE", "REMOVE", "RENAME", "CHMOD"}
+
+func (op Op) String() string {
+	var names []string
+	for i, name := range opNames {
+		if op&(1<<uint(i)) != 0 {
+			names = append(names, name)
+		}
+	}
+	return strings.Join(names, "|")
+}
+
+// A Change describes the operations on a single file that triggered a task.
+type Change struct {
+	Name string
+	Op   Op // Every operation observed on Name while debouncing.
+}
+
+type changesKey struct{}
+
+// Changes returns the files changed to trigger the task running with ctx,
+// in the order they were first changed.
+// Tasks must be registered with TaskContext to receive a Context.
+//
+// If the task was not triggered by Start, nil is returned.
+func Changes(ctx context.Context) []Change {
+	cs, _ := ctx.Value(changesKey{}).([]Change)
+	return cs
+}
+
+func withChanges(ctx context.Context, cs []Change) context.Context {
+	return context.WithValue(ctx, changesKey{}, cs)
+}
+
+// changes accumulates Changes, merging the operations of those with the same name.
+type changes struct {
+	cs []Change
+	i  map[string]int
+}
+
+func (c *changes) add(ch Change) {
+	if c.i == nil {
+		c.i = make(map[string]int)
+	}
+	if i, ok := c.i[ch.Name]; ok {
+		c.cs[i].Op |= ch.Op
+		return
+	}
+	c.i[ch.Name] = len(c.cs)
+	c.cs = append(c.cs, ch)
+}
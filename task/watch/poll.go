@@ -0,0 +1,164 @@
+package watch
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// poller is a Backend which periodically compares the contents of each directory with its previous state.
+type poller struct {
+	sync.Mutex
+	dirs   map[string]map[string]os.FileInfo
+	events chan Change
+	errors chan error
+	done   chan struct{}
+	once   sync.Once
+}
+
+// NewPoller returns a Backend which stats the contents of every watched directory once per interval.
+// It works wherever the operating system's filesystem events do not,
+// such as on network mounts, at the cost of latency and CPU time.
+//
+// Writes are detected by changes to a file's size or modification time,
+// and renames are reported as a Remove followed by a Create.
+func NewPoller(interval time.Duration) Backend {
+	p := &poller{
+		dirs:   make(map[string]map[string]os.FileInfo),
+		events: make(chan Change),
+		errors: make(chan error),
+		done:   make(chan struct{}),
+	}
+	go p.poll(interval)
+	return p
+}
+
+func (p *poller) Add(dir string) error {
+	files, err := scan(dir)
+	if err != nil {
+		return err
+	}
+	p.Lock()
+	p.dirs[dir] = files
+	p.Unlock()
+	return nil
+}
+
+func (p *poller) Remove(dir string) error {
+	p.Lock()
+	delete(p.dirs, dir)
+	p.Unlock()
+	return nil
+}
+
+func (p *poller) Events() <-chan Change {
+	return p.events
+}
+
+func (p *poller) Errors() <-chan error {
+	return p.errors
+}
+
+func (p *poller) Close() error {
+	p.once.Do(func() {
+		close(p.done)
+	})
+	return nil
+}
+
+func (p *poller) poll(interval time.Duration) {
+	t := time.NewTicker(interval)
+	defer func() {
+		t.Stop()
+		close(p.events)
+		close(p.errors)
+	}()
+	for {
+		select {
+		case <-p.done:
+			return
+		case <-t.C:
+		}
+		p.Lock()
+		dirs := make([]string, 0, len(p.dirs))
+		for dir := range p.dirs {
+			dirs = append(dirs, dir)
+		}
+		p.Unlock()
+		for _, dir := range dirs {
+			if !p.compare(dir) {
+				return
+			}
+		}
+	}
+}
+
+// compare rescans dir and sends a Change for every difference from its previous state.
+// It returns false if the poller was closed while sending.
+func (p *poller) compare(dir string) bool {
+	files, err := scan(dir)
+	p.Lock()
+	old, ok := p.dirs[dir]
+	if ok && err == nil {
+		p.dirs[dir] = files
+	}
+	p.Unlock()
+	if !ok || os.IsNotExist(err) {
+		// The removal is reported by the parent directory, if it is watched.
+		return true
+	}
+	if err != nil {
+		return p.send(nil, err)
+	}
+	for name := range old {
+		if _, ok := files[name]; !ok && !p.send(&Change{Name: name, Op: Remove}, nil) {
+			return false
+		}
+	}
+	for name, fi := range files {
+		var op Op
+		if prev, ok := old[name]; !ok {
+			op = Create
+		} else if !fi.IsDir() && (prev.Size() != fi.Size() || !prev.ModTime().Equal(fi.ModTime())) {
+			op = Write
+		} else if prev.Mode() != fi.Mode() {
+			op = Chmod
+		}
+		if op != 0 && !p.send(&Change{Name: name, Op: op}, nil) {
+			return false
+		}
+	}
+	return true
+}
+
+func (p *poller) send(c *Change, err error) bool {
+	if c != nil {
+		select {
+		case p.events <- *c:
+			return true
+		case <-p.done:
+			return false
+		}
+	}
+	select {
+	case p.errors <- err:
+		return true
+	case <-p.done:
+		return false
+	}
+}
+
+// scan returns the FileInfo of every file in dir, keyed by path.
+func scan(dir string) (map[string]os.FileInfo, error) {
+	fis, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	files := make(map[string]os.FileInfo, len(fis))
+	for _, fi := range fis {
+		files[filepath.Join(dir, fi.Name())] = fi
+	}
+	return files, nil
+}
@@ -0,0 +1,97 @@
+package watch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/SaidinWoT/gulf/task"
+)
+
+// ErrNoCommand is returned by Serve if it is not given a command to run.
+var ErrNoCommand = errors.New("You defined a server without a command")
+
+// Serve registers a task named name which starts cmd as a long-running process,
+// stopping any process it previously started, and watches patterns to execute it.
+// Because deps are dependencies of the task, the process is only restarted once they succeed.
+//
+// The process is interrupted and given the Set's Grace period to exit before being killed.
+// It is not started until the task is first executed.
+func (s *Set) Serve(name string, patterns []string, cmd []string, deps ...string) error {
+	if len(cmd) == 0 {
+		return ErrNoCommand
+	}
+	sv := &server{
+		out: s.Set.Stream,
+		c: task.Command{
+			Name:  cmd[0],
+			Args:  cmd[1:],
+			Grace: s.grace,
+		},
+	}
+	if err := s.TaskContext(name, sv.restart, deps...); err != nil {
+		return err
+	}
+	s.Watch(patterns, name)
+	s.mu.Lock()
+	s.servers = append(s.servers, sv)
+	s.mu.Unlock()
+	return nil
+}
+
+// server manages a single long-running process.
+type server struct {
+	sync.Mutex
+	c      task.Command
+	out    func(name string) io.WriteCloser
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// restart stops the running process, if any, and starts a new one.
+// The process outlives ctx, so it writes to a stream of the task's output
+// rather than the task's Writer, which is only flushed when the task returns.
+func (sv *server) restart(ctx context.Context) error {
+	sv.Lock()
+	defer sv.Unlock()
+	sv.stop()
+	ctx, sv.cancel = context.WithCancel(context.WithoutCancel(ctx))
+	sv.done = make(chan struct{})
+	w := sv.out(task.Name(ctx))
+	c := sv.c
+	c.Output = w
+	go func(ctx context.Context, done chan struct{}) {
+		err := c.Run(ctx)
+		if err != nil && ctx.Err() == nil {
+			fmt.Fprintln(w, err)
+		}
+		w.Close()
+		close(done)
+	}(ctx, sv.done)
+	return nil
+}
+
+// stopServers stops every process started by Serve.
+func (s *Set) stopServers() {
+	s.mu.Lock()
+	servers := s.servers
+	s.mu.Unlock()
+	for _, sv := range servers {
+		sv.Lock()
+		sv.stop()
+		sv.Unlock()
+	}
+}
+
+// stop stops the running process and waits for it to exit.
+// The caller must hold sv's lock.
+func (sv *server) stop() {
+	if sv.cancel == nil {
+		return
+	}
+	sv.cancel()
+	<-sv.done
+	sv.cancel, sv.done = nil, nil
+}
@@ -0,0 +1,87 @@
+package watch_test
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/SaidinWoT/gulf/task"
+	. "github.com/SaidinWoT/gulf/task/watch"
+)
+
+// lockedBuffer is a bytes.Buffer safe for output written by running servers.
+type lockedBuffer struct {
+	sync.Mutex
+	b bytes.Buffer
+}
+
+func (l *lockedBuffer) Write(p []byte) (int, error) {
+	l.Lock()
+	defer l.Unlock()
+	return l.b.Write(p)
+}
+
+func (l *lockedBuffer) String() string {
+	l.Lock()
+	defer l.Unlock()
+	return l.b.String()
+}
+
+// waitOutput reports whether out contains line at least n times within a second.
+func waitOutput(out *lockedBuffer, line string, n int) bool {
+	deadline := time.Now().Add(time.Second)
+	for strings.Count(out.String(), line) < n {
+		if time.Now().After(deadline) {
+			return false
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	return true
+}
+
+func TestServeRestart(t *testing.T) {
+	chdir(t)
+	s := New()
+	out := new(lockedBuffer)
+	s.Set.SetOption(task.Output(out))
+	s.SetOption(Grace(time.Second))
+	s.Serve("web", []string{"*.go"}, []string{"sh", "-c", "trap 'echo stopped; exit 0' TERM; echo started; sleep 5 & wait"})
+	b, _ := start(t, s)
+
+	b.Send(Change{Name: "a.go", Op: Write})
+	if !waitOutput(out, "[web] started\n", 1) {
+		t.Fatalf("The server was not started: %q", out.String())
+	}
+	b.Send(Change{Name: "a.go", Op: Write})
+	if !waitOutput(out, "[web] started\n", 2) {
+		t.Fatalf("The server was not restarted: %q", out.String())
+	}
+	// The previous process is interrupted and exits gracefully before the next starts.
+	if got := out.String(); !strings.Contains(got, "[web] started\n[web] stopped\n[web] started\n") {
+		t.Errorf("The server was not stopped before restarting: %q", got)
+	}
+}
+
+func TestServeOutput(t *testing.T) {
+	chdir(t)
+	s := New()
+	out := new(lockedBuffer)
+	s.Set.SetOption(task.Output(out), task.Buffer(true))
+	s.Serve("web", []string{"*.go"}, []string{"sh", "-c", "echo ready; printf partial; sleep 5"})
+	start(t, s)
+	if err := s.Exec("web"); err != nil {
+		t.Fatal(err)
+	}
+	if !waitOutput(out, "[web] ready\n", 1) {
+		t.Fatalf("Server output was held while buffering: %q", out.String())
+	}
+	// Restarting stops the previous process, writing its partial line.
+	if err := s.Exec("web"); err != nil {
+		t.Fatal(err)
+	}
+	if got := out.String(); !strings.Contains(got, "[web] partial\n") {
+		t.Errorf("The server's partial line was not written once it stopped: %q", got)
+	}
+}
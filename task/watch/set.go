@@ -1,6 +1,8 @@
 package watch
 
 import (
+	"context"
+	"sync"
 	"time"
 
 	"github.com/SaidinWoT/gulf/glob"
@@ -11,20 +13,32 @@ import (
 // execute tasks based on filesystem events.
 type Set struct {
 	*task.Set
-	match func(string, string) (bool, error)
-	glob  func(string) ([]string, error)
-	wm    map[string][]string
-	delay time.Duration
+	match       func(string, string) (bool, error)
+	patternOpts []glob.Option
+	glob        func(string) ([]string, error)
+	ignore      *glob.Ignore
+	regs        []registration
+	delay       time.Duration
+	grace       time.Duration
+	policy      Policy
+	errors      func(error)
+	result      func(string, error)
+	backend     func() (Backend, error)
+
+	mu      sync.Mutex
+	stop    context.CancelFunc
+	servers []*server
 }
 
-// New returns a new Set, initialized to use glob.Match and glob.Glob.
+// New returns a new Set, initialized to use patterns compiled by glob.Compile and glob.Glob.
 func New() *Set {
 	return &Set{
-		Set:   task.New(),
-		match: glob.Match,
-		glob:  glob.Glob,
-		wm:    make(map[string][]string),
-		delay: 10 * time.Millisecond,
+		Set:     task.New(),
+		glob:    glob.Glob,
+		delay:   10 * time.Millisecond,
+		grace:   5 * time.Second,
+		policy:  Queue,
+		backend: NewNotify,
 	}
 }
 
@@ -54,7 +68,7 @@ func Globber(fn func(string) ([]string, error)) Option {
 // Matcher sets the matching function used by the Set to assert that
 // events on a file should trigger tasks.
 //
-// The default is glob.Match
+// The default matches patterns compiled once by glob.Compile, as glob.Match would.
 func Matcher(fn func(string, string) (bool, error)) Option {
 	return func(s *Set) error {
 		s.match = fn
@@ -62,6 +76,30 @@ func Matcher(fn func(string, string) (bool, error)) Option {
 	}
 }
 
+// Patterns sets the glob.Options with which the Set compiles patterns
+// for matching events, such as glob.CaseInsensitive or glob.MatchBase.
+// They are not used with a Matcher.
+//
+// The default compiles patterns with no Options
+func Patterns(opts ...glob.Option) Option {
+	return func(s *Set) error {
+		s.patternOpts = opts
+		return nil
+	}
+}
+
+// Ignore sets the Set to disregard events on names ignored by the provided ignore files
+// (glob.DefaultIgnoreFiles, if none are provided) beneath the working directory,
+// and to not watch ignored directories.
+//
+// The default ignores nothing
+func Ignore(files ...string) Option {
+	return func(s *Set) error {
+		s.ignore = glob.NewIgnore(".", files...)
+		return nil
+	}
+}
+
 // Delay sets the delay used by Watch to identify unique file events.
 // All file events on a single file name (not inode) that take place within d time
 // of each other will be considered a single file event by Watch.
@@ -75,3 +113,77 @@ func Delay(d time.Duration) Option {
 		return nil
 	}
 }
+
+// Grace sets the time processes started by Serve are given to exit
+// after being interrupted, before they are killed.
+// It applies to servers registered after the Option is set.
+//
+// The default is 5 seconds
+func Grace(d time.Duration) Option {
+	return func(s *Set) error {
+		s.grace = d
+		return nil
+	}
+}
+
+// Policy determines how a Set handles a task triggered while it is already running.
+type Policy int
+
+const (
+	// Queue waits for the running execution to finish before executing the task once more.
+	Queue Policy = iota
+	// Restart cancels the Context of the running execution and executes the task again once it returns.
+	Restart
+)
+
+// Running sets the Policy for tasks triggered while they are already running.
+// No matter the Policy, a task triggered by Start never runs concurrently with itself,
+// and any number of triggers while it runs result in a single further execution.
+//
+// The default is Queue
+func Running(p Policy) Option {
+	return func(s *Set) error {
+		s.policy = p
+		return nil
+	}
+}
+
+// Errors sets a function to be called with any error encountered while watching,
+// such as a failure to watch a directory.
+//
+// The default ignores them
+func Errors(fn func(error)) Option {
+	return func(s *Set) error {
+		s.errors = fn
+		return nil
+	}
+}
+
+// Results sets a function to be called with the name and result of every task
+// executed because of a filesystem event.
+//
+// The default ignores them
+func Results(fn func(name string, err error)) Option {
+	return func(s *Set) error {
+		s.result = fn
+		return nil
+	}
+}
+
+// Watcher sets the function used to create the Backend which reports filesystem events.
+//
+// The default is NewNotify
+func Watcher(fn func() (Backend, error)) Option {
+	return func(s *Set) error {
+		s.backend = fn
+		return nil
+	}
+}
+
+// Poll sets the Set to watch the filesystem by polling every interval, rather than with fsnotify.
+// It is shorthand for Watcher with NewPoller.
+func Poll(interval time.Duration) Option {
+	return Watcher(func() (Backend, error) {
+		return NewPoller(interval), nil
+	})
+}
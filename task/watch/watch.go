@@ -2,94 +2,416 @@
 package watch
 
 import (
+	"context"
+	"os"
 	"path/filepath"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/SaidinWoT/gulf/glob"
-	"gopkg.in/fsnotify.v1"
 )
 
 type timers struct {
-	sync.RWMutex
-	m     map[string]*time.Timer
-	delay time.Duration
-	exec  func(string) error
+	sync.Mutex
+	wg      sync.WaitGroup
+	ctx     context.Context
+	stopped bool
+	m       map[string]*pending
+	delay   time.Duration
+	policy  Policy
+	exec    func(context.Context, string) error
+	result  func(string, error)
+}
+
+// pending tracks the debounced and running executions of a single task.
+type pending struct {
+	t       *time.Timer
+	cs      changes // Changes not yet provided to an execution.
+	running bool
+	queued  bool
+	cancel  context.CancelFunc
+}
+
+// registration correlates a set of patterns with the tasks executed when they change.
+type registration struct {
+	patterns []string
+	set      *glob.PatternSet // The compiled patterns, when no Matcher is set.
+	tasks    []string
+	ops      Op
 }
 
 // Watch adds a set of tasks to the list that will be executed when any of the provided patterns are matched.
 func (s *Set) Watch(patterns []string, tasks ...string) {
-	for _, p := range patterns {
-		s.wm[p] = append(s.wm[p], tasks...)
-	}
+	s.WatchOp(All, patterns, tasks...)
+}
+
+// WatchOp is like Watch, but only executes the tasks for the operations in ops.
+// For example, WatchOp(Create|Remove, ...) ignores writes to existing files.
+func (s *Set) WatchOp(ops Op, patterns []string, tasks ...string) {
+	s.regs = append(s.regs, registration{
+		patterns: patterns,
+		tasks:    tasks,
+		ops:      ops,
+	})
 }
 
-// Start begins watching all of the directories containing files added to s.
+// Start begins watching all of the directories beneath the prefixes of patterns added to s.
+// Only directories as deep as a pattern could match are watched:
+// "src/*/*.go" watches src and its immediate subdirectories, while a globstar watches the entire tree beneath its prefix.
+// Directories created while watching are watched as well, and those removed are forgotten.
+//
+// Start blocks until Stop is called; see Run.
 func (s *Set) Start() error {
+	return s.Run(context.Background())
+}
+
+// Stop causes a running call to Start or Run to return.
+func (s *Set) Stop() {
+	s.mu.Lock()
+	if s.stop != nil {
+		s.stop()
+	}
+	s.mu.Unlock()
+}
+
+// Run is like Start, but watches until ctx is done or Stop is called.
+// Before returning, it cancels the Contexts of any running tasks and waits for them to return,
+// and stops any processes started by Serve.
+func (s *Set) Run(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	s.mu.Lock()
+	s.stop = cancel
+	s.mu.Unlock()
+	if err := s.compile(); err != nil {
+		return err
+	}
 	w, err := s.dirWatcher()
 	if err != nil {
 		return err
 	}
+	defer w.Close()
+	defer s.stopServers()
 
 	ts := &timers{
-		m:     make(map[string]*time.Timer),
-		delay: s.delay,
-		exec:  s.Exec,
+		ctx:    ctx,
+		m:      make(map[string]*pending),
+		delay:  s.delay,
+		policy: s.policy,
+		exec:   s.ExecContext,
+		result: s.result,
 	}
+	defer func() {
+		// Running executions must be canceled before waiting for them to return.
+		cancel()
+		ts.stop()
+	}()
 
+	errs := w.Errors()
 	for {
 		select {
-		case event := <-w.Events:
-			for p, tasks := range s.wm {
-				if matches, _ := s.match(p, event.Name); matches {
-					for _, task := range tasks {
-						go ts.debounce(task)
-					}
-				}
+		case <-ctx.Done():
+			return nil
+		case c, ok := <-w.Events():
+			if !ok {
+				return nil
 			}
-		case _ = <-w.Errors:
+			c.Name = filepath.Clean(c.Name)
+			s.trigger(ts, c)
+			// Files may be created in a new directory before it is watched.
+			for _, name := range w.update(c) {
+				s.trigger(ts, Change{Name: name, Op: Create})
+			}
+		case err, ok := <-errs:
+			// A closed channel would otherwise be selected continually.
+			if !ok {
+				errs = nil
+			} else if s.errors != nil {
+				s.errors(err)
+			}
+		}
+	}
+}
+
+// trigger debounces every task watching a pattern which matches the changed file.
+// Each task only receives the operations that its registration selected.
+func (s *Set) trigger(ts *timers, c Change) {
+	if s.ignore != nil && s.ignore.Match(c.Name) {
+		return
+	}
+	for _, r := range s.regs {
+		op := c.Op & r.ops
+		if op == 0 {
+			continue
+		}
+		if !s.matches(r, c.Name) {
+			continue
 		}
+		for _, task := range r.tasks {
+			ts.debounce(task, Change{Name: c.Name, Op: op})
+		}
+	}
+}
+
+// matches reports whether name is included by the registration's patterns.
+// As with glob.Parse, patterns are processed in order, and those beginning with '!'
+// exclude matches of preceding patterns but not those which follow them.
+func (s *Set) matches(r registration, name string) bool {
+	if s.match == nil {
+		return r.set.Match(name)
+	}
+	var included bool
+	for _, p := range r.patterns {
+		p, negated := glob.Negation(p)
+		// Only patterns which could change the outcome need to be matched.
+		if included != negated {
+			continue
+		}
+		if matches, _ := s.match(p, name); matches {
+			included = !negated
+		}
+	}
+	return included
+}
+
+// compile compiles the patterns of every registration into a glob.PatternSet, unless the Set has a Matcher.
+func (s *Set) compile() error {
+	if s.match != nil {
+		return nil
+	}
+	for i, r := range s.regs {
+		set, err := glob.CompileSet(r.patterns, s.patternOpts...)
+		if err != nil {
+			return err
+		}
+		s.regs[i].set = set
 	}
+	return nil
 }
 
-func (s *Set) dirWatcher() (*fsnotify.Watcher, error) {
-	w, err := fsnotify.NewWatcher()
+// watcher wraps a Backend to keep track of the directories it is watching.
+type watcher struct {
+	Backend
+	dirs   map[string]struct{}
+	roots  map[string]int // The depth to watch beneath each root, or -1 for unlimited.
+	ignore *glob.Ignore
+	errors func(error)
+}
+
+// dirWatcher creates a watcher on every directory beneath the longest definite prefix of each pattern,
+// as well as those containing files matched by the Set's globbing function.
+func (s *Set) dirWatcher() (*watcher, error) {
+	b, err := s.backend()
 	if err != nil {
 		return nil, err
 	}
-
+	w := &watcher{
+		Backend: b,
+		dirs:    make(map[string]struct{}),
+		roots:   make(map[string]int),
+		ignore:  s.ignore,
+		errors:  s.errors,
+	}
 	var paths []string
-	for path := range s.wm {
-		paths = append(paths, path)
+	for _, r := range s.regs {
+		for _, p := range r.patterns {
+			paths = append(paths, p)
+			if _, negated := glob.Negation(p); negated {
+				continue
+			}
+			prefix := glob.Prefix(p, glob.SpecialRunes)
+			rest := filepath.FromSlash(p[len(prefix):])
+			depth := strings.Count(rest, string(filepath.Separator))
+			if strings.Contains(rest, "**") {
+				depth = -1
+			}
+			w.root(filepath.Clean(prefix), depth)
+		}
+	}
+	// The globbing function may find files beyond the prefixes.
+	matches, err := glob.Parse(s.glob, paths...)
+	if err != nil {
+		w.error(err)
 	}
-	dirs := make(map[string]struct{})
-	for _, path := range glob.Parse(s.glob, paths...) {
-		dirs[filepath.Dir(path)] = struct{}{}
+	for _, path := range matches {
+		w.root(filepath.Dir(path), 0)
 	}
-	for dir := range dirs {
-		w.Add(dir)
+	for root := range w.roots {
+		w.addAll(root)
 	}
 	return w, nil
 }
 
+// root records that directories up to depth beneath dir should be watched.
+func (w *watcher) root(dir string, depth int) {
+	if d, ok := w.roots[dir]; ok && (d < 0 || (depth >= 0 && d >= depth)) {
+		return
+	}
+	w.roots[dir] = depth
+}
+
+// within reports whether dir is within the depth of one of the watcher's roots.
+func (w *watcher) within(dir string) bool {
+	for root, depth := range w.roots {
+		rel, err := filepath.Rel(root, dir)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			continue
+		}
+		if depth < 0 || rel == "." || strings.Count(rel, string(filepath.Separator)) < depth {
+			return true
+		}
+	}
+	return false
+}
+
+// addAll watches root and every directory beneath it within the watcher's roots, except for dot directories.
+// It returns the names of all files found along the way.
+func (w *watcher) addAll(root string) []string {
+	var files []string
+	filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil
+		}
+		if !info.IsDir() {
+			files = append(files, path)
+			return nil
+		}
+		if path != root && (strings.HasPrefix(info.Name(), ".") || w.ignore != nil && w.ignore.Match(path)) || !w.within(path) {
+			return filepath.SkipDir
+		}
+		if _, ok := w.dirs[path]; ok {
+			return nil
+		}
+		if err := w.Add(path); err != nil {
+			w.error(err)
+			return nil
+		}
+		w.dirs[path] = struct{}{}
+		return nil
+	})
+	return files
+}
+
+func (w *watcher) error(err error) {
+	if w.errors != nil {
+		w.errors(err)
+	}
+}
+
+// update adds watches for directories created by c and removes those for directories it removed.
+// It returns the names of any files found in newly watched directories.
+func (w *watcher) update(c Change) []string {
+	name := c.Name
+	switch {
+	case c.Op&Create != 0:
+		if info, err := os.Stat(name); err == nil && info.IsDir() {
+			return w.addAll(name)
+		}
+	case c.Op&(Remove|Rename) != 0:
+		if _, ok := w.dirs[name]; !ok {
+			return nil
+		}
+		prefix := name + string(filepath.Separator)
+		for dir := range w.dirs {
+			if dir == name || strings.HasPrefix(dir, prefix) {
+				w.Remove(dir)
+				delete(w.dirs, dir)
+			}
+		}
+	}
+	return nil
+}
+
 // debounce creates an AfterFunc to execute the provided task
 // or postpones its execution if the AfterFunc already exists.
 // This behavior is intended to deal with the series of events
 // that many popular text editors issue when writing a file.
-func (ts *timers) debounce(name string) {
-	ts.RLock()
-	t, ok := ts.m[name]
-	ts.RUnlock()
-	if ok {
-		t.Reset(ts.delay)
+//
+// Every change recorded before the task executes is provided to it.
+func (ts *timers) debounce(name string, c Change) {
+	ts.Lock()
+	defer ts.Unlock()
+	p, ok := ts.m[name]
+	if !ok {
+		p = new(pending)
+		ts.m[name] = p
+	}
+	p.cs.add(c)
+	if p.t != nil {
+		p.t.Reset(ts.delay)
 		return
 	}
-	ts.Lock()
-	ts.m[name] = time.AfterFunc(ts.delay, func() {
+	var t *time.Timer
+	t = time.AfterFunc(ts.delay, func() {
 		ts.Lock()
-		delete(ts.m, name)
-		ts.Unlock()
-		ts.exec(name)
+		defer ts.Unlock()
+		// A Reset racing with expiry may fire the timer a second time.
+		if p.t != t {
+			return
+		}
+		p.t = nil
+		if ts.stopped {
+			return
+		}
+		if !p.running {
+			ts.wg.Add(1)
+			go ts.run(ts.begin(p), name, p)
+			p.running = true
+			return
+		}
+		// At most one execution runs at a time; the changes wait for the next.
+		p.queued = true
+		if ts.policy == Restart {
+			p.cancel()
+		}
 	})
+	p.t = t
+}
+
+// begin takes the changes recorded for p and creates the Context of its next execution.
+// The Context is canceled by p.cancel, which is set before any further trigger could call it.
+// ts must be locked.
+func (ts *timers) begin(p *pending) context.Context {
+	cs := p.cs.cs
+	p.cs = changes{}
+	ctx, cancel := context.WithCancel(ts.ctx)
+	p.cancel = cancel
+	return withChanges(ctx, cs)
+}
+
+// run executes the task with ctx, then executes it again if it was triggered in the meantime.
+func (ts *timers) run(ctx context.Context, name string, p *pending) {
+	for {
+		err := ts.exec(ctx, name)
+		if ts.result != nil {
+			ts.result(name, err)
+		}
+		ts.Lock()
+		p.cancel()
+		if !p.queued || ts.stopped {
+			break
+		}
+		p.queued = false
+		ctx = ts.begin(p)
+		ts.Unlock()
+	}
+	p.running = false
+	ts.Unlock()
+	ts.wg.Done()
+}
+
+// stop prevents any further executions and waits for those running to return.
+// Running executions are canceled along with ts.ctx.
+func (ts *timers) stop() {
+	ts.Lock()
+	ts.stopped = true
+	for _, p := range ts.m {
+		if p.t != nil {
+			p.t.Stop()
+			p.t = nil
+		}
+	}
 	ts.Unlock()
+	ts.wg.Wait()
 }
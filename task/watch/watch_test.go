@@ -0,0 +1,455 @@
+package watch_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/SaidinWoT/gulf/glob"
+	"github.com/SaidinWoT/gulf/task"
+	. "github.com/SaidinWoT/gulf/task/watch"
+	"github.com/SaidinWoT/gulf/task/watch/watchtest"
+)
+
+// chdir changes into a temporary directory containing dirs for the duration of the test.
+func chdir(t *testing.T, dirs ...string) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmp := t.TempDir()
+	for _, dir := range dirs {
+		os.MkdirAll(filepath.Join(tmp, dir), 0755)
+	}
+	os.Chdir(tmp)
+	t.Cleanup(func() {
+		os.Chdir(wd)
+	})
+}
+
+type result struct {
+	name    string
+	changes []Change
+}
+
+// start runs s with a fake Backend, returning it along with a channel receiving
+// the changes provided to each execution of the named tasks.
+func start(t *testing.T, s *Set, tasks ...string) (*watchtest.Backend, <-chan result) {
+	b := watchtest.New()
+	rs := make(chan result, 10)
+	for _, name := range tasks {
+		name := name
+		s.TaskContext(name, func(ctx context.Context) error {
+			rs <- result{name, Changes(ctx)}
+			return nil
+		})
+	}
+	s.SetOption(Watcher(b.Func()), Delay(time.Millisecond))
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- s.Run(ctx)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+	// Run adds the directories before reading any events.
+	b.Error(nil)
+	return b, rs
+}
+
+func expect(t *testing.T, rs <-chan result, r result) {
+	select {
+	case got := <-rs:
+		if !reflect.DeepEqual(got, r) {
+			t.Errorf("Executed %v, expected %v.", got, r)
+		}
+	case <-time.After(time.Second):
+		t.Errorf("%s was not executed.", r.name)
+	}
+}
+
+func expectNone(t *testing.T, rs <-chan result) {
+	select {
+	case got := <-rs:
+		t.Errorf("Unexpectedly executed %v.", got)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestWatchNegation(t *testing.T) {
+	for name, opts := range map[string][]Option{
+		"compiled": nil,
+		"Matcher":  {Matcher(glob.Match)},
+	} {
+		t.Run(name, func(t *testing.T) {
+			chdir(t, "src/a")
+			s := New()
+			s.SetOption(opts...)
+			s.Watch([]string{"src/**/*.go", "!src/**/*_test.go", "src/keep_test.go"}, "build")
+			b, rs := start(t, s, "build")
+
+			b.Send(Change{Name: "src/a/x_test.go", Op: Write})
+			expectNone(t, rs)
+			b.Send(Change{Name: "src/keep_test.go", Op: Write})
+			expect(t, rs, result{"build", []Change{{"src/keep_test.go", Write}}})
+			b.Send(Change{Name: "src/a/x.go", Op: Write})
+			expect(t, rs, result{"build", []Change{{"src/a/x.go", Write}}})
+		})
+	}
+}
+
+func TestWatchPatterns(t *testing.T) {
+	chdir(t, "src")
+	s := New()
+	s.SetOption(Patterns(glob.CaseInsensitive(true), glob.Dot(true)))
+	s.Watch([]string{"src/*.GO"}, "build")
+	b, rs := start(t, s, "build")
+
+	b.Send(Change{Name: "src/.a.go", Op: Write})
+	expect(t, rs, result{"build", []Change{{"src/.a.go", Write}}})
+}
+
+func TestWatchOp(t *testing.T) {
+	chdir(t, "src")
+	s := New()
+	s.Watch([]string{"src/*.go"}, "build")
+	s.WatchOp(Create|Remove, []string{"src/*"}, "index")
+	b, rs := start(t, s, "build", "index")
+
+	b.Send(Change{Name: "src/README", Op: Chmod})
+	expectNone(t, rs)
+
+	// Each registration only provides the operations it selected.
+	b.Send(Change{Name: "src/b.go", Op: Create | Write})
+	got := map[string]result{}
+	for i := 0; i < 2; i++ {
+		select {
+		case r := <-rs:
+			got[r.name] = r
+		case <-time.After(time.Second):
+			t.Fatal("Tasks were not executed.")
+		}
+	}
+	expected := map[string]result{
+		"build": {"build", []Change{{"src/b.go", Create | Write}}},
+		"index": {"index", []Change{{"src/b.go", Create}}},
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Executed %v, expected %v.", got, expected)
+	}
+
+	b.Send(Change{Name: "src/b.go", Op: Write})
+	expect(t, rs, result{"build", []Change{{"src/b.go", Write}}})
+	expectNone(t, rs)
+}
+
+func TestChanges(t *testing.T) {
+	chdir(t, "src/a")
+	s := New()
+	s.Watch([]string{"src/**/*.go"}, "build")
+	b, rs := start(t, s, "build")
+
+	// Changes within the debounce window are coalesced, merging the operations on each file.
+	b.Send(Change{Name: "src/a/x.go", Op: Write})
+	b.Send(Change{Name: "src/b.go", Op: Create})
+	b.Send(Change{Name: "src/a/x.go", Op: Chmod})
+	expect(t, rs, result{"build", []Change{{"src/a/x.go", Write | Chmod}, {"src/b.go", Create}}})
+	expectNone(t, rs)
+
+	// Each execution only receives the changes since the last.
+	b.Send(Change{Name: "src/b.go", Op: Remove})
+	expect(t, rs, result{"build", []Change{{"src/b.go", Remove}}})
+}
+
+func TestWatchDirs(t *testing.T) {
+	chdir(t, "src/a/b", "src/.git", "other")
+	s := New()
+	s.Watch([]string{"src/**/*.go"}, "build")
+	b, _ := start(t, s, "build")
+	if dirs := b.Dirs(); !reflect.DeepEqual(dirs, []string{"src", "src/a", "src/a/b"}) {
+		t.Errorf("Watched directories %v.", dirs)
+	}
+}
+
+func TestWatchNewDirectory(t *testing.T) {
+	chdir(t, "src")
+	s := New()
+	s.Watch([]string{"src/**/*.go"}, "build")
+	b, rs := start(t, s, "build")
+
+	os.MkdirAll("src/new/deeper", 0755)
+	os.WriteFile("src/new/deeper/x.go", nil, 0644)
+	b.Send(Change{Name: "src/new", Op: Create})
+	expect(t, rs, result{"build", []Change{{"src/new/deeper/x.go", Create}}})
+	if dirs := b.Dirs(); !reflect.DeepEqual(dirs, []string{"src", "src/new", "src/new/deeper"}) {
+		t.Errorf("Watched directories %v after creation.", dirs)
+	}
+
+	b.Send(Change{Name: "src/new", Op: Remove})
+	b.Error(nil)
+	if dirs := b.Dirs(); !reflect.DeepEqual(dirs, []string{"src"}) {
+		t.Errorf("Watched directories %v after removal.", dirs)
+	}
+}
+
+func TestStop(t *testing.T) {
+	chdir(t)
+	s := New()
+	b := watchtest.New()
+	s.SetOption(Watcher(b.Func()))
+	done := make(chan error)
+	go func() {
+		done <- s.Start()
+	}()
+	b.Error(nil)
+	s.Stop()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Error(err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Start did not return after Stop.")
+	}
+	if !b.Closed() {
+		t.Error("The Backend was not closed.")
+	}
+}
+
+func TestPoll(t *testing.T) {
+	dir := t.TempDir()
+	p := NewPoller(5 * time.Millisecond)
+	defer p.Close()
+	if err := p.Add(dir); err != nil {
+		t.Fatal(err)
+	}
+	name := filepath.Join(dir, "x")
+	os.WriteFile(name, nil, 0644)
+	select {
+	case c := <-p.Events():
+		if c != (Change{Name: name, Op: Create}) {
+			t.Errorf("Poller reported %v.", c)
+		}
+	case <-time.After(time.Second):
+		t.Error("Poller did not report a created file.")
+	}
+}
+
+func TestWatchDepth(t *testing.T) {
+	chdir(t, "a/b/c", "src/a/b")
+	s := New()
+	s.Watch([]string{"*.go", "src/*/*.go"}, "build")
+	b, _ := start(t, s, "build")
+	if dirs := b.Dirs(); !reflect.DeepEqual(dirs, []string{".", "src", "src/a"}) {
+		t.Errorf("Watched directories %v.", dirs)
+	}
+}
+
+func TestClosedErrors(t *testing.T) {
+	chdir(t, "src")
+	s := New()
+	s.Watch([]string{"src/*.go"}, "build")
+	b, rs := start(t, s, "build")
+	b.CloseErrors()
+	b.Send(Change{Name: "src/a.go", Op: Write})
+	expect(t, rs, result{"build", []Change{{"src/a.go", Write}}})
+}
+
+func TestStopDuringRun(t *testing.T) {
+	chdir(t, "src")
+	s := New()
+	s.Watch([]string{"src/*.go"}, "reload")
+	s.Task("reload", func() error {
+		s.Stop()
+		return nil
+	})
+	b := watchtest.New()
+	s.SetOption(Watcher(b.Func()), Delay(time.Millisecond))
+	done := make(chan error)
+	go func() {
+		done <- s.Run(context.Background())
+	}()
+	b.Error(nil)
+	b.Send(Change{Name: "src/a.go", Op: Write})
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Error(err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Run did not return after a task called Stop.")
+	}
+}
+
+func TestQueue(t *testing.T) {
+	chdir(t, "src")
+	s := New()
+	s.Watch([]string{"src/*.go"}, "build")
+	rs := make(chan result, 10)
+	release := make(chan struct{})
+	s.TaskContext("build", func(ctx context.Context) error {
+		rs <- result{"build", Changes(ctx)}
+		<-release
+		return nil
+	})
+	b, _ := start(t, s)
+
+	b.Send(Change{Name: "src/a.go", Op: Write})
+	expect(t, rs, result{"build", []Change{{"src/a.go", Write}}})
+	b.Send(Change{Name: "src/b.go", Op: Write})
+	b.Send(Change{Name: "src/c.go", Op: Create})
+	// Let both triggers fire while the first execution is running.
+	time.Sleep(50 * time.Millisecond)
+	expectNone(t, rs)
+	close(release)
+	expect(t, rs, result{"build", []Change{{"src/b.go", Write}, {"src/c.go", Create}}})
+	expectNone(t, rs)
+}
+
+func TestRestart(t *testing.T) {
+	chdir(t, "src")
+	s := New()
+	s.SetOption(Running(Restart))
+	s.Watch([]string{"src/*.go"}, "build")
+	rs := make(chan result, 10)
+	canceled := make(chan struct{})
+	var runs int32
+	s.TaskContext("build", func(ctx context.Context) error {
+		rs <- result{"build", Changes(ctx)}
+		if atomic.AddInt32(&runs, 1) > 1 {
+			return nil
+		}
+		<-ctx.Done()
+		close(canceled)
+		return ctx.Err()
+	})
+	b, _ := start(t, s)
+
+	b.Send(Change{Name: "src/a.go", Op: Write})
+	expect(t, rs, result{"build", []Change{{"src/a.go", Write}}})
+	b.Send(Change{Name: "src/b.go", Op: Write})
+	select {
+	case <-canceled:
+	case <-time.After(time.Second):
+		t.Fatal("The running execution was not canceled.")
+	}
+	expect(t, rs, result{"build", []Change{{"src/b.go", Write}}})
+	expectNone(t, rs)
+}
+
+func TestRestartRapid(t *testing.T) {
+	chdir(t, "src")
+	s := New()
+	s.Watch([]string{"src/*.go"}, "build")
+	s.TaskContext("build", func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	b := watchtest.New()
+	s.SetOption(Watcher(b.Func()), Delay(0), Running(Restart))
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- s.Run(ctx)
+	}()
+	b.Error(nil)
+	// Each trigger may restart an execution which has only just begun.
+	for i := 0; i < 20; i++ {
+		b.Send(Change{Name: "src/a.go", Op: Write})
+	}
+	cancel()
+	if err := <-done; err != nil {
+		t.Error(err)
+	}
+}
+
+func TestClosedEvents(t *testing.T) {
+	chdir(t, "src")
+	s := New()
+	s.Watch([]string{"src/*.go"}, "build")
+	started := make(chan struct{})
+	s.TaskContext("build", func(ctx context.Context) error {
+		close(started)
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	b := watchtest.New()
+	s.SetOption(Watcher(b.Func()), Delay(time.Millisecond))
+	done := make(chan error)
+	go func() {
+		done <- s.Run(context.Background())
+	}()
+	b.Error(nil)
+	b.Send(Change{Name: "src/a.go", Op: Write})
+	<-started
+	b.CloseEvents()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Run did not cancel the running task after the Backend's events closed.")
+	}
+}
+
+func TestResultsAndErrors(t *testing.T) {
+	chdir(t, "src")
+	s := New()
+	s.Watch([]string{"src/*.go"}, "pass", "fail")
+	fail := errors.New("fail")
+	s.Task("pass", func() error { return nil })
+	s.Task("fail", func() error { return fail })
+	var mu sync.Mutex
+	results := make(map[string]error)
+	errs := make(chan error, 10)
+	s.SetOption(
+		Results(func(name string, err error) {
+			mu.Lock()
+			results[name] = err
+			mu.Unlock()
+		}),
+		Errors(func(err error) {
+			if err != nil {
+				errs <- err
+			}
+		}),
+	)
+	b, _ := start(t, s)
+
+	watchErr := errors.New("watch")
+	b.Error(watchErr)
+	select {
+	case err := <-errs:
+		if err != watchErr {
+			t.Errorf("Errors received %v.", err)
+		}
+	case <-time.After(time.Second):
+		t.Error("Errors was not called.")
+	}
+
+	b.Send(Change{Name: "src/a.go", Op: Write})
+	deadline := time.Now().Add(time.Second)
+	for {
+		mu.Lock()
+		n := len(results)
+		mu.Unlock()
+		if n == 2 || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if err, ok := results["pass"]; !ok || err != nil {
+		t.Errorf("Results received %v for the passing task.", err)
+	}
+	if e, ok := results["fail"].(*task.ErrExec); !ok || e.Task != fail {
+		t.Errorf("Results received %v for the failing task.", results["fail"])
+	}
+}
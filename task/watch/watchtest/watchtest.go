@@ -0,0 +1,105 @@
+// Package watchtest provides a fake watch.Backend for deterministic tests of watched tasks.
+package watchtest
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/SaidinWoT/gulf/task/watch"
+)
+
+// Backend is a watch.Backend which reports only the Changes and errors sent to it.
+type Backend struct {
+	sync.Mutex
+	dirs   map[string]bool
+	events chan watch.Change
+	errors chan error
+	closed bool
+}
+
+// New returns a Backend with no directories watched.
+func New() *Backend {
+	return &Backend{
+		dirs:   make(map[string]bool),
+		events: make(chan watch.Change),
+		errors: make(chan error),
+	}
+}
+
+// Func returns a function suitable for watch.Watcher which always provides b.
+func (b *Backend) Func() func() (watch.Backend, error) {
+	return func() (watch.Backend, error) {
+		return b, nil
+	}
+}
+
+// Send reports c to the Set watching b, blocking until it has been received.
+func (b *Backend) Send(c watch.Change) {
+	b.events <- c
+}
+
+// Error reports err to the Set watching b, blocking until it has been received.
+func (b *Backend) Error(err error) {
+	b.errors <- err
+}
+
+// CloseErrors closes the channel returned by Errors, as a Backend that can no longer fail might.
+// Error must not be called afterward.
+func (b *Backend) CloseErrors() {
+	close(b.errors)
+}
+
+// CloseEvents closes the channel returned by Events, as a Backend that has failed might.
+// Send must not be called afterward.
+func (b *Backend) CloseEvents() {
+	close(b.events)
+}
+
+// Dirs returns the sorted list of directories currently watched.
+func (b *Backend) Dirs() []string {
+	b.Lock()
+	defer b.Unlock()
+	var dirs []string
+	for dir := range b.dirs {
+		dirs = append(dirs, dir)
+	}
+	sort.Strings(dirs)
+	return dirs
+}
+
+// Closed reports whether Close has been called.
+func (b *Backend) Closed() bool {
+	b.Lock()
+	defer b.Unlock()
+	return b.closed
+}
+
+func (b *Backend) Add(dir string) error {
+	b.Lock()
+	b.dirs[dir] = true
+	b.Unlock()
+	return nil
+}
+
+func (b *Backend) Remove(dir string) error {
+	b.Lock()
+	delete(b.dirs, dir)
+	b.Unlock()
+	return nil
+}
+
+func (b *Backend) Events() <-chan watch.Change {
+	return b.events
+}
+
+func (b *Backend) Errors() <-chan error {
+	return b.errors
+}
+
+// Close marks b as closed. Its channels are left open, so that Send never panics.
+func (b *Backend) Close() error {
+	b.Lock()
+	b.closed = true
+	b.Unlock()
+	return nil
+}
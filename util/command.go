@@ -0,0 +1,97 @@
+package util
+
+import (
+	"bytes"
+	"io"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/SaidinWoT/gulf/stream"
+)
+
+// NamePlaceholder is replaced by a ReadNamer's name in the arguments given to Command.
+const NamePlaceholder = "{name}"
+
+// ErrCommand indicates that the command run by a Command Transform failed for a single file.
+type ErrCommand struct {
+	File   string // The name of the ReadNamer being transformed.
+	Args   []string
+	Stderr string // Anything the command wrote to its standard error.
+	Err    error
+}
+
+func (e ErrCommand) Error() string {
+	msg := e.File + ": " + strings.Join(e.Args, " ") + ": " + e.Err.Error()
+	if s := strings.TrimSpace(e.Stderr); s != "" {
+		msg += ": " + s
+	}
+	return msg
+}
+
+func (e ErrCommand) Unwrap() error {
+	return e.Err
+}
+
+// Command returns a Transform that pipes the contents of each ReadNamer through
+// the standard input of the named program, replacing them with its standard output.
+// Every occurrence of NamePlaceholder in args is replaced with the ReadNamer's name.
+//
+// At most runtime.NumCPU() instances of the program run at once; see CommandN.
+func Command(name string, args ...string) stream.Transform {
+	return CommandN(runtime.NumCPU(), name, args...)
+}
+
+// CommandN is like Command, but runs at most n instances of the program at once.
+//
+// If the program fails or exits with a non-zero status, reading the resulting
+// ReadNamer returns an ErrCommand once all of the program's output has been read.
+func CommandN(n int, name string, args ...string) stream.Transform {
+	if n < 1 {
+		n = 1
+	}
+	sem := make(chan struct{}, n)
+	return func(s stream.Stream) stream.Stream {
+		t := make(chan stream.ReadNamer, len(s))
+		go func() {
+			for r := range s {
+				// Acquiring in order ensures the running commands are always
+				// the earliest ones, which a sequential reader is waiting on.
+				sem <- struct{}{}
+				pr, pw := io.Pipe()
+				go func(r stream.ReadNamer) {
+					pw.CloseWithError(command(r, pw, name, args))
+					<-sem
+				}(r)
+				t <- stream.NamedReader{
+					Reader:     pr,
+					NameString: r.Name(),
+				}
+			}
+			close(t)
+		}()
+		return t
+	}
+}
+
+func command(r stream.ReadNamer, w io.Writer, name string, args []string) error {
+	if c, ok := r.(io.Closer); ok {
+		defer c.Close()
+	}
+	args = append([]string(nil), args...)
+	for i, arg := range args {
+		args[i] = strings.Replace(arg, NamePlaceholder, r.Name(), -1)
+	}
+	stderr := new(bytes.Buffer)
+	cmd := exec.Command(name, args...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = r, w, stderr
+	if err := cmd.Run(); err != nil {
+		return ErrCommand{
+			File:   r.Name(),
+			Args:   append([]string{name}, args...),
+			Stderr: stderr.String(),
+			Err:    err,
+		}
+	}
+	return nil
+}
@@ -0,0 +1,32 @@
+package util_test
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"testing"
+
+	"github.com/SaidinWoT/gulf/stream"
+	. "github.com/SaidinWoT/gulf/util"
+)
+
+func TestCommand(t *testing.T) {
+	s := stream.Src(
+		stream.NamedReader{Reader: bytes.NewBufferString("abc"), NameString: "a.txt"},
+		stream.NamedReader{Reader: bytes.NewBufferString("def"), NameString: "fail.txt"},
+	).Pipe(CommandN(1, "sh", "-c", `test "$1" = fail.txt && exit 1; echo "$1"; tr a-z A-Z`, "sh", "{name}"))
+
+	r := <-s
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		t.Error(err)
+	}
+	if string(b) != "a.txt\nABC" {
+		t.Errorf("Command produced %q for %s.", b, r.Name())
+	}
+	r = <-s
+	_, err = io.Copy(ioutil.Discard, r)
+	if _, ok := err.(ErrCommand); !ok {
+		t.Errorf("Command reported %v for %s rather than an ErrCommand.", err, r.Name())
+	}
+}